@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/search"
+	"stock-news-aggregator/internal/services"
+)
+
+// SavedSearchRequest represents the request body for creating a saved search
+type SavedSearchRequest struct {
+	Name    string   `json:"name"`
+	Query   string   `json:"query"`
+	Sources []string `json:"sources"`
+	Tickers []string `json:"tickers"`
+}
+
+func createSavedSearch(c *gin.Context) {
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	saved, err := services.CreateSavedSearch(models.SavedSearch{
+		Name:    req.Name,
+		Query:   req.Query,
+		Sources: req.Sources,
+		Tickers: req.Tickers,
+	})
+	var parseErr *search.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": parseErr, "query": req.Query})
+		return
+	case errors.Is(err, services.ErrEmptySavedSearch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, saved)
+}
+
+func getSavedSearches(c *gin.Context) {
+	searches, err := database.GetSavedSearches()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, searches)
+}
+
+func deleteSavedSearch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	err = database.DeleteSavedSearch(id)
+	if err == database.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func getAlerts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200 // Maximum number of alerts
+	}
+
+	var savedSearchID int64
+	if value := c.Query("savedSearchId"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid savedSearchId"})
+			return
+		}
+		savedSearchID = id
+	}
+
+	alerts, err := database.GetAlerts(savedSearchID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, alerts)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"stock-news-aggregator/internal/services"
+)
+
+func getDigest(c *gin.Context) {
+	date, err := services.ParseDigestDate(c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > services.MaxDigestStories {
+		limit = services.MaxDigestStories
+	}
+
+	digest, err := services.GetDigest(date, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, digest)
+}
@@ -0,0 +1,229 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func createAlertTables() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS saved_searches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			query TEXT NOT NULL DEFAULT '',
+			sources TEXT NOT NULL DEFAULT '',
+			tickers TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			saved_search_id INTEGER NOT NULL,
+			article_id INTEGER NOT NULL,
+			matched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(saved_search_id, article_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_alerts_matched ON alerts(matched_at);
+	`)
+	return err
+}
+
+// splitList and joinList store small string lists as comma-separated text
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func joinList(list []string) string {
+	return strings.Join(list, ",")
+}
+
+// InsertSavedSearch stores a saved search and returns its ID
+func InsertSavedSearch(s models.SavedSearch) (int64, error) {
+	res, err := db.Exec(`
+		INSERT INTO saved_searches (name, query, sources, tickers)
+		VALUES (?, ?, ?, ?)`,
+		s.Name, s.Query, joinList(s.Sources), joinList(s.Tickers))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func scanSavedSearch(row rowScanner) (models.SavedSearch, error) {
+	var s models.SavedSearch
+	var sources, tickers string
+	err := row.Scan(&s.ID, &s.Name, &s.Query, &sources, &tickers, &s.CreatedAt)
+	s.Sources = splitList(sources)
+	s.Tickers = splitList(tickers)
+	return s, err
+}
+
+// GetSavedSearches returns every saved search, oldest first
+func GetSavedSearches() ([]models.SavedSearch, error) {
+	rows, err := db.Query(`SELECT id, name, query, sources, tickers, created_at FROM saved_searches ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	searches := []models.SavedSearch{}
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, s)
+	}
+	return searches, rows.Err()
+}
+
+// GetSavedSearch returns a single saved search, or ErrNotFound
+func GetSavedSearch(id int64) (*models.SavedSearch, error) {
+	s, err := scanSavedSearch(db.QueryRow(`
+		SELECT id, name, query, sources, tickers, created_at FROM saved_searches WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DeleteSavedSearch removes a saved search and its alerts, or returns ErrNotFound
+func DeleteSavedSearch(id int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM saved_searches WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM alerts WHERE saved_search_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MatchArticles returns which of the given article IDs match the filter
+func MatchArticles(ids []int64, filter ArticleFilter) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	whereClause, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+	idClause := `id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + `)`
+	if whereClause == "" {
+		whereClause = ` WHERE ` + idClause
+	} else {
+		whereClause += ` AND ` + idClause
+	}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	rows, err := db.Query(`SELECT id FROM articles`+whereClause+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matched []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		matched = append(matched, id)
+	}
+	return matched, rows.Err()
+}
+
+// InsertAlerts records that the articles matched a saved search. Repeated
+// matches of the same article are ignored.
+func InsertAlerts(savedSearchID int64, articleIDs []int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, articleID := range articleIDs {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO alerts (saved_search_id, article_id) VALUES (?, ?)`,
+			savedSearchID, articleID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAlerts returns the most recent alerts, newest first, optionally for a
+// single saved search (savedSearchID 0 means all)
+func GetAlerts(savedSearchID int64, limit int) ([]models.Alert, error) {
+	query := `
+		SELECT alerts.id, alerts.saved_search_id, saved_searches.name, alerts.matched_at, alerts.article_id
+		FROM alerts
+		JOIN saved_searches ON saved_searches.id = alerts.saved_search_id
+		JOIN articles ON articles.id = alerts.article_id`
+	var args []interface{}
+	if savedSearchID != 0 {
+		query += ` WHERE alerts.saved_search_id = ?`
+		args = append(args, savedSearchID)
+	}
+	query += ` ORDER BY alerts.matched_at DESC, alerts.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []models.Alert{}
+	var articleIDs []int64
+	for rows.Next() {
+		var alert models.Alert
+		var articleID int64
+		err := rows.Scan(&alert.ID, &alert.SavedSearchID, &alert.SavedSearchName, &alert.MatchedAt, &articleID)
+		if err != nil {
+			return nil, err
+		}
+		alert.Article.ID = articleID
+		alerts = append(alerts, alert)
+		articleIDs = append(articleIDs, articleID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	articles, err := GetArticlesByIDs(articleIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+	for i := range alerts {
+		alerts[i].Article = models.NewArticleDTO(byID[alerts[i].Article.ID])
+	}
+	return alerts, nil
+}
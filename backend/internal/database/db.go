@@ -2,18 +2,30 @@ package database
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 	_ "github.com/mattn/go-sqlite3"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/search"
 )
 
 var db *sql.DB
 
+// ErrNotFound is returned when a requested row does not exist
+var ErrNotFound = errors.New("not found")
+
+// dbFile is the path the database was opened from, used for size reporting
+var dbFile string
+
 func InitDB(dbPath string) error {
 	var err error
 	db, err = sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return err
 	}
+	dbFile = dbPath
 
 	// Create articles table
 	_, err = db.Exec(`
@@ -33,50 +45,305 @@ func InitDB(dbPath string) error {
 		return err
 	}
 
+	// Columns added after the initial schema
+	if err := ensureColumn("articles", "image_url", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn("articles", "section", "TEXT"); err != nil {
+		return err
+	}
+	if err := backfillSections(); err != nil {
+		return err
+	}
+
+	// Indexes backing the filters and sort orders in GetArticles
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_articles_published ON articles(datetime(published_at));
+		CREATE INDEX IF NOT EXISTS idx_articles_source_published ON articles(source, datetime(published_at));
+		CREATE INDEX IF NOT EXISTS idx_articles_last_scraped ON articles(last_scraped_at);
+		CREATE INDEX IF NOT EXISTS idx_articles_section ON articles(section);
+	`)
+	if err != nil {
+		return err
+	}
+
+	if err := createAlertTables(); err != nil {
+		return err
+	}
+
+	if err := createTickerTables(); err != nil {
+		return err
+	}
+
+	if err := createEventTables(); err != nil {
+		return err
+	}
+
+	if err := createFactTables(); err != nil {
+		return err
+	}
+
+	if err := createSummaryTables(); err != nil {
+		return err
+	}
+
+	if err := createSentimentColumns(); err != nil {
+		return err
+	}
+
+	// Create retention run log table
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS retention_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ran_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			source TEXT NOT NULL,
+			content_stripped INTEGER NOT NULL DEFAULT 0,
+			archived INTEGER NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// ensureColumn adds a column to an existing table if it is missing
+func ensureColumn(table, column, definition string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
 func GetDB() *sql.DB {
 	return db
 }
 
-func InsertArticle(title, url, source, content, description string, publishedAt time.Time) error {
-	_, err := db.Exec(`
+// InsertArticle stores a new article and returns its ID. It returns 0 if an
+// article with the same URL already exists.
+func InsertArticle(article models.Article) (int64, error) {
+	if article.Section == "" {
+		article.Section = sectionFromURL(article.URL)
+	}
+
+	res, err := db.Exec(`
 		INSERT OR IGNORE INTO articles (
-			title, url, source, content, description, published_at, last_scraped_at
-		) VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
-	`, title, url, source, content, description, publishedAt)
-	return err
+			title, url, source, content, description, image_url, section, published_at, last_scraped_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, article.Title, article.URL, article.Source.Name, article.Content, article.Description,
+		article.ImageURL, article.Section, article.PublishedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return 0, nil
+	}
+	return res.LastInsertId()
 }
 
-func GetArticles(page, pageSize int, search string) ([]Article, int, error) {
-	var articles []Article
-	var totalCount int
+// articleColumns is the column list read by scanArticle
+const articleColumns = `id, title, url, source, COALESCE(content, ''), COALESCE(description, ''),
+	COALESCE(image_url, ''), COALESCE(section, ''), published_at, created_at, last_scraped_at,
+	sentiment_score, COALESCE(sentiment_label, '')`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanArticle(row rowScanner) (models.Article, error) {
+	var article models.Article
+	var sentimentScore sql.NullFloat64
+	var sentimentLabel string
+	err := row.Scan(
+		&article.ID,
+		&article.Title,
+		&article.URL,
+		&article.Source.Name,
+		&article.Content,
+		&article.Description,
+		&article.ImageURL,
+		&article.Section,
+		&article.PublishedAt,
+		&article.CreatedAt,
+		&article.LastScrapedAt,
+		&sentimentScore,
+		&sentimentLabel,
+	)
+	if sentimentScore.Valid {
+		article.Sentiment = &models.ArticleSentiment{Score: sentimentScore.Float64, Label: sentimentLabel}
+	}
+	return article, err
+}
+
+// Sort orders accepted by ArticleFilter.Sort
+const (
+	SortPublished = "published"
+	SortRelevance = "relevance"
+	SortScraped   = "scraped"
+)
+
+// publishedAt normalizes published_at to UTC so rows stored with different
+// offsets compare correctly; the indexes below are built on the same expression
+const publishedAt = `datetime(published_at)`
+
+// ArticleFilter narrows down and orders the articles returned by GetArticles
+type ArticleFilter struct {
+	Search     string
+	Query      search.Node // parsed advanced query, nil for none
+	Sources    []string
+	From       time.Time // inclusive lower bound on published_at, zero for none
+	To         time.Time // exclusive upper bound on published_at, zero for none
+	ByAge      bool      // bound and sort undated articles by when they were stored, as retention does
+	Sort       string
+	HasContent *bool
+	Events     []string // event types, any of which an article must have
+}
 
-	// Get total count with search condition
-	countQuery := `SELECT COUNT(*) FROM articles`
-	whereClause := ""
-	args := []interface{}{}
-	
-	if search != "" {
-		// Search in both title and content fields
-		whereClause = ` WHERE (title LIKE ? OR content LIKE ? OR description LIKE ?)`
-		searchTerm := "%" + search + "%"
+// where builds the WHERE clause and its arguments for the filter
+func (f ArticleFilter) where() (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	if f.Search != "" {
+		// Search in title, content and description fields
+		conditions = append(conditions, `(title LIKE ? ESCAPE '\' OR content LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`)
+		searchTerm := likePattern(f.Search)
 		args = append(args, searchTerm, searchTerm, searchTerm)
 	}
-	
-	err := db.QueryRow(countQuery+whereClause, args...).Scan(&totalCount)
+
+	if f.Query != nil {
+		clause, queryArgs, err := compileQuery(f.Query)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, clause)
+		args = append(args, queryArgs...)
+	}
+
+	if len(f.Sources) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(f.Sources)), ",")
+		conditions = append(conditions, `source IN (`+placeholders+`)`)
+		for _, source := range f.Sources {
+			args = append(args, source)
+		}
+	}
+
+	if len(f.Events) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(f.Events)), ",")
+		conditions = append(conditions, `id IN (SELECT article_id FROM article_events WHERE event IN (`+placeholders+`))`)
+		for _, event := range f.Events {
+			args = append(args, event)
+		}
+	}
+
+	dated := publishedAt
+	if f.ByAge {
+		dated = articleAge
+	}
+	if !f.From.IsZero() {
+		conditions = append(conditions, dated+` >= ?`)
+		args = append(args, sqliteTime(f.From))
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, dated+` < ?`)
+		args = append(args, sqliteTime(f.To))
+	}
+
+	if f.HasContent != nil {
+		if *f.HasContent {
+			conditions = append(conditions, `(content IS NOT NULL AND content != '')`)
+		} else {
+			conditions = append(conditions, `(content IS NULL OR content = '')`)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+	return ` WHERE ` + strings.Join(conditions, " AND "), args, nil
+}
+
+// orderBy builds the ORDER BY clause and its arguments for the filter
+func (f ArticleFilter) orderBy() (string, []interface{}) {
+	switch f.Sort {
+	case SortScraped:
+		return ` ORDER BY last_scraped_at DESC, id DESC`, nil
+	case SortRelevance:
+		var keywords []string
+		if f.Search != "" {
+			keywords = append(keywords, f.Search)
+		}
+		if f.Query != nil {
+			keywords = append(keywords, search.Keywords(f.Query)...)
+		}
+		if len(keywords) == 0 {
+			break
+		}
+
+		// Title matches outrank description matches, which outrank content matches
+		var scores []string
+		var args []interface{}
+		for _, keyword := range keywords {
+			pattern := likePattern(keyword)
+			scores = append(scores, `CASE WHEN title LIKE ? ESCAPE '\' THEN 3 ELSE 0 END +
+			CASE WHEN COALESCE(description, '') LIKE ? ESCAPE '\' THEN 2 ELSE 0 END +
+			CASE WHEN COALESCE(content, '') LIKE ? ESCAPE '\' THEN 1 ELSE 0 END`)
+			args = append(args, pattern, pattern, pattern)
+		}
+		return ` ORDER BY (` + strings.Join(scores, " + ") + `) DESC, ` + publishedAt + ` DESC, id DESC`, args
+	}
+	if f.ByAge {
+		return ` ORDER BY ` + articleAge + ` DESC, id DESC`, nil
+	}
+	return ` ORDER BY ` + publishedAt + ` DESC, id DESC`, nil
+}
+
+// sqliteTime formats t the way SQLite's datetime() does, in UTC
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+func GetArticles(page, pageSize int, filter ArticleFilter) ([]models.Article, int, error) {
+	var articles []models.Article
+	var totalCount int
+
+	// Get total count with filter conditions
+	whereClause, args, err := filter.where()
+	if err != nil {
+		return nil, 0, err
+	}
+	err = db.QueryRow(`SELECT COUNT(*) FROM articles`+whereClause, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Get paginated results with search
+	// Get paginated results with filter conditions
+	orderClause, orderArgs := filter.orderBy()
 	query := `
-		SELECT id, title, url, source, content, description, published_at, created_at, last_scraped_at 
-		FROM articles` + whereClause + `
-		ORDER BY published_at DESC
+		SELECT ` + articleColumns + `
+		FROM articles` + whereClause + orderClause + `
 		LIMIT ? OFFSET ?`
 
+	args = append(args, orderArgs...)
 	args = append(args, pageSize, (page-1)*pageSize)
 	rows, err := db.Query(query, args...)
 	if err != nil {
@@ -85,41 +352,193 @@ func GetArticles(page, pageSize int, search string) ([]Article, int, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		var article Article
-		err := rows.Scan(
-			&article.ID,
-			&article.Title,
-			&article.URL,
-			&article.Source,
-			&article.Content,
-			&article.Description,
-			&article.PublishedAt,
-			&article.CreatedAt,
-			&article.LastScrapedAt,
-		)
+		article, err := scanArticle(rows)
 		if err != nil {
 			return nil, 0, err
 		}
 		articles = append(articles, article)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
+	if err := attachLinks(articles); err != nil {
+		return nil, 0, err
+	}
 	return articles, totalCount, nil
 }
 
+// CountArticlesBySource counts the articles matching the filter per source,
+// ordered by source
+func CountArticlesBySource(filter ArticleFilter) ([]FacetCount, error) {
+	whereClause, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+	return facetCounts(`SELECT source, COUNT(*) FROM articles`+whereClause+` GROUP BY source ORDER BY source`, args)
+}
+
+// GetArticleIDs returns the IDs of up to limit articles matching the filter,
+// in the filter's sort order, skipping the first offset
+func GetArticleIDs(filter ArticleFilter, offset, limit int) ([]int64, error) {
+	whereClause, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+	orderClause, orderArgs := filter.orderBy()
+	args = append(args, orderArgs...)
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(`SELECT id FROM articles`+whereClause+orderClause+` LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetArticlesByIDs loads the given articles, returned in the order of ids.
+// IDs that no longer exist are skipped.
+func GetArticlesByIDs(ids []int64) ([]models.Article, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := db.Query(`SELECT `+articleColumns+` FROM articles WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]models.Article, len(ids))
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		byID[article.ID] = article
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	articles := make([]models.Article, 0, len(ids))
+	for _, id := range ids {
+		if article, ok := byID[id]; ok {
+			articles = append(articles, article)
+		}
+	}
+	if err := attachLinks(articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// attachLinks fills in the companies and event types of the given articles
+func attachLinks(articles []models.Article) error {
+	if err := attachTickers(articles); err != nil {
+		return err
+	}
+	return attachEvents(articles)
+}
+
+// ScanArticles calls fn for every stored article in ID order, stopping at
+// the first error fn returns
+func ScanArticles(fn func(models.Article) error) error {
+	rows, err := db.Query(`SELECT ` + articleColumns + ` FROM articles ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(article); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetArticlesSince returns the articles published at or after since, using
+// the time they were stored for articles without a publish date
+func GetArticlesSince(since time.Time) ([]models.Article, error) {
+	rows, err := db.Query(`SELECT `+articleColumns+` FROM articles WHERE `+articleAge+` >= ? ORDER BY id`, sqliteTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []models.Article
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+	return articles, rows.Err()
+}
+
+// GetRecentTitles returns the titles of the most recently published articles
+func GetRecentTitles(limit int) ([]string, error) {
+	rows, err := db.Query(`SELECT title FROM articles ORDER BY `+publishedAt+` DESC, id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+	return titles, rows.Err()
+}
+
+// GetArticleByID returns a single stored article, or ErrNotFound
+func GetArticleByID(id int64) (*models.Article, error) {
+	article, err := scanArticle(db.QueryRow(`SELECT `+articleColumns+` FROM articles WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	articles := []models.Article{article}
+	if err := attachLinks(articles); err != nil {
+		return nil, err
+	}
+	if err := attachFacts(&articles[0]); err != nil {
+		return nil, err
+	}
+	return &articles[0], nil
+}
+
 func IsArticleScraped(url string) (bool, error) {
 	var exists bool
 	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM articles WHERE url = ?)", url).Scan(&exists)
 	return exists, err
 }
-
-type Article struct {
-	ID            int64      `json:"id"`
-	Title         string     `json:"title"`
-	URL           string     `json:"url"`
-	Source        string     `json:"source"`
-	Content       string     `json:"content"`
-	Description   string     `json:"description"`
-	PublishedAt   time.Time  `json:"publishedAt"`
-	CreatedAt     time.Time  `json:"createdAt"`
-	LastScrapedAt time.Time  `json:"lastScrapedAt"`
-} 
\ No newline at end of file
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/models"
+)
+
+// openTestDB opens a fresh database for one test
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := InitDB(filepath.Join(t.TempDir(), "news.db")); err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+}
+
+// insertTestArticle stores an article and returns its ID
+func insertTestArticle(t *testing.T, article models.Article) int64 {
+	t.Helper()
+	if article.URL == "" {
+		article.URL = "https://example.com/" + article.Title
+	}
+	if article.Source.Name == "" {
+		article.Source.Name = "Livemint"
+	}
+	if article.PublishedAt.IsZero() {
+		article.PublishedAt = time.Now()
+	}
+	id, err := InsertArticle(article)
+	if err != nil {
+		t.Fatalf("InsertArticle(%q) error = %v", article.Title, err)
+	}
+	return id
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func createEventTables() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS article_events (
+			article_id INTEGER NOT NULL,
+			event TEXT NOT NULL,
+			score REAL NOT NULL,
+			PRIMARY KEY (article_id, event)
+		);
+		CREATE INDEX IF NOT EXISTS idx_article_events_event ON article_events(event, article_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// The rule set an article was last classified with, so changed rules
+	// reclassify everything
+	return ensureColumn("articles", "events_version", "TEXT")
+}
+
+// SaveArticleEvents replaces the event types of an article and records the
+// rule set version they were found with
+func SaveArticleEvents(articleID int64, events []models.ArticleEvent, version string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM article_events WHERE article_id = ?`, articleID); err != nil {
+		return fmt.Errorf("failed to clear events: %v", err)
+	}
+	for _, e := range events {
+		_, err := tx.Exec(`INSERT INTO article_events (article_id, event, score) VALUES (?, ?, ?)`,
+			articleID, e.Type, e.Score)
+		if err != nil {
+			return fmt.Errorf("failed to store event %s: %v", e.Type, err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE articles SET events_version = ? WHERE id = ?`, version, articleID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetArticlesToClassify returns up to limit articles not yet classified with
+// the given rule set version
+func GetArticlesToClassify(version string, limit int) ([]models.Article, error) {
+	rows, err := db.Query(`
+		SELECT `+articleColumns+` FROM articles
+		WHERE COALESCE(events_version, '') != ?
+		ORDER BY id LIMIT ?`, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []models.Article
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+	return articles, rows.Err()
+}
+
+// attachEvents fills in the event types of the given articles, highest
+// scoring first
+func attachEvents(articles []models.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(articles)), ",")
+	args := make([]interface{}, len(articles))
+	index := make(map[int64]int, len(articles))
+	for i, article := range articles {
+		args[i] = article.ID
+		index[article.ID] = i
+	}
+
+	rows, err := db.Query(`
+		SELECT article_id, event, score FROM article_events
+		WHERE article_id IN (`+placeholders+`)
+		ORDER BY score DESC, event`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load events: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var articleID int64
+		var e models.ArticleEvent
+		if err := rows.Scan(&articleID, &e.Type, &e.Score); err != nil {
+			return err
+		}
+		i := index[articleID]
+		articles[i].Events = append(articles[i].Events, e)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func TestArticleFilterEvents(t *testing.T) {
+	openTestDB(t)
+	results := insertTestArticle(t, models.Article{Title: "TCS Q2 results beat estimates"})
+	payout := insertTestArticle(t, models.Article{Title: "ITC declares interim dividend with results"})
+	insertTestArticle(t, models.Article{Title: "Sensex ends flat"})
+	if err := SaveArticleEvents(results, []models.ArticleEvent{{Type: "earnings", Score: 3}}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveArticleEvents(payout, []models.ArticleEvent{{Type: "dividend", Score: 4}, {Type: "earnings", Score: 2}}, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Articles of any of the types are returned, each once
+	tests := []struct {
+		events []string
+		want   []int64
+	}{
+		{[]string{"earnings"}, []int64{payout, results}},
+		{[]string{"dividend"}, []int64{payout}},
+		{[]string{"dividend", "earnings"}, []int64{payout, results}},
+		{[]string{"ipo"}, []int64{}},
+	}
+	for _, tt := range tests {
+		articles, total, err := GetArticles(1, 10, ArticleFilter{Events: tt.events})
+		if err != nil {
+			t.Fatalf("GetArticles(%v) error = %v", tt.events, err)
+		}
+		got := []int64{}
+		for _, article := range articles {
+			got = append(got, article.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) || total != len(tt.want) {
+			t.Errorf("events %v: got %v of %d, want %v", tt.events, got, total, tt.want)
+		}
+	}
+}
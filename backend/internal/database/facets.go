@@ -0,0 +1,169 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// FacetCount is the number of matching articles for one facet value
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets breaks the articles matching a filter down by source, publish day
+// and section
+type Facets struct {
+	Sources  []FacetCount `json:"sources"`
+	Days     []FacetCount `json:"days"`
+	Sections []FacetCount `json:"sections"`
+}
+
+// maxDayFacets caps how many publish days are reported, newest first
+const maxDayFacets = 30
+
+// GetFacets counts the articles matching the filter per source, per publish
+// day in loc, and per section
+func GetFacets(filter ArticleFilter, loc *time.Location) (*Facets, error) {
+	whereClause, args, err := filter.where()
+	if err != nil {
+		return nil, err
+	}
+
+	// IST has no daylight saving, so a fixed offset is enough to bucket by day
+	_, offset := time.Now().In(loc).Zone()
+	day := fmt.Sprintf(`date(%s, '%+d seconds')`, publishedAt, offset)
+
+	// Scrapers that cannot read a publish date leave the zero time behind
+	dayWhere := ` WHERE ` + publishedAt + ` >= '1971-01-01'`
+	if whereClause != "" {
+		dayWhere = whereClause + ` AND ` + publishedAt + ` >= '1971-01-01'`
+	}
+
+	facets := &Facets{}
+
+	facets.Sources, err = facetCounts(`
+		SELECT source, COUNT(*) FROM articles`+whereClause+`
+		GROUP BY source ORDER BY COUNT(*) DESC, source`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	facets.Days, err = facetCounts(`
+		SELECT `+day+` AS day, COUNT(*) FROM articles`+dayWhere+`
+		GROUP BY day ORDER BY day DESC LIMIT `+fmt.Sprint(maxDayFacets), args)
+	if err != nil {
+		return nil, err
+	}
+
+	facets.Sections, err = facetCounts(`
+		SELECT COALESCE(section, ''), COUNT(*) FROM articles`+whereClause+`
+		GROUP BY COALESCE(section, '') ORDER BY COUNT(*) DESC, COALESCE(section, '')`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+func facetCounts(query string, args []interface{}) ([]FacetCount, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []FacetCount{}
+	for rows.Next() {
+		var fc FacetCount
+		if err := rows.Scan(&fc.Value, &fc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, fc)
+	}
+	return counts, rows.Err()
+}
+
+// sectionNoise are path segments that say nothing about an article's section
+var sectionNoise = map[string]bool{
+	"news":        true,
+	"business":    true,
+	"photos":      true,
+	"articleshow": true,
+	"slideshow":   true,
+	"amp":         true,
+}
+
+// sectionFromURL derives a section such as "markets/stocks" from the path
+// segments that precede the article slug
+func sectionFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	var parts []string
+	for _, segment := range strings.Split(strings.Trim(u.Path, "/"), "/") {
+		segment = strings.ToLower(segment)
+		if segment == "" {
+			continue
+		}
+		if isSlug(segment) {
+			break
+		}
+		if !sectionNoise[segment] {
+			parts = append(parts, segment)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// isSlug reports whether a path segment looks like an article slug or ID
+// rather than a section name
+func isSlug(segment string) bool {
+	if strings.HasSuffix(segment, ".html") || strings.HasSuffix(segment, ".cms") {
+		return true
+	}
+	if strings.Count(segment, "-") >= 4 {
+		return true
+	}
+	return strings.IndexFunc(segment, func(r rune) bool { return r < '0' || r > '9' }) < 0
+}
+
+// backfillSections fills in the section of articles stored before the column existed
+func backfillSections() error {
+	rows, err := db.Query(`SELECT id, url FROM articles WHERE section IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	sections := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var articleURL string
+		if err := rows.Scan(&id, &articleURL); err != nil {
+			rows.Close()
+			return err
+		}
+		sections[id] = sectionFromURL(articleURL)
+	}
+	rows.Close()
+	if len(sections) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for id, section := range sections {
+		if _, err := tx.Exec(`UPDATE articles SET section = ? WHERE id = ?`, section, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
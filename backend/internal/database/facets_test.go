@@ -0,0 +1,101 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func TestSectionFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://economictimes.indiatimes.com/markets/stocks/news/tata-motors-shares-jump-on-jlr-sales/articleshow/112233.cms", "markets/stocks"},
+		{"https://www.livemint.com/market/stock-market-news/sensex-rises-200-points-on-bank-gains-11697000000000.html", "market/stock-market-news"},
+		{"https://www.business-standard.com/markets/news/nifty-ends-higher-on-bank-gains-124101800123_1.html", "markets"},
+		{"https://www.moneycontrol.com/news/business/markets/sensex-nifty-live-updates-12345678.html", "markets"},
+		{"https://groww.in/market-news/stocks/123456", "market-news/stocks"},
+		{"https://example.com/", ""},
+		{"://not a url", ""},
+	}
+	for _, tt := range tests {
+		if got := sectionFromURL(tt.url); got != tt.want {
+			t.Errorf("sectionFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestBackfillSections(t *testing.T) {
+	openTestDB(t)
+	id := insertTestArticle(t, models.Article{Title: "Sensex rises", URL: "https://economictimes.indiatimes.com/markets/stocks/news/sensex-rises-on-bank-gains/articleshow/1.cms"})
+	if _, err := db.Exec(`UPDATE articles SET section = NULL WHERE id = ?`, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backfillSections(); err != nil {
+		t.Fatalf("backfillSections() error = %v", err)
+	}
+	article, err := GetArticleByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.Section != "markets/stocks" {
+		t.Errorf("backfilled section = %q, want markets/stocks", article.Section)
+	}
+}
+
+func TestGetFacets(t *testing.T) {
+	openTestDB(t)
+	ist := time.FixedZone("IST", 5*3600+1800)
+
+	// 20:00 UTC is already the next day in IST
+	insertTestArticle(t, models.Article{Title: "Late ET story", Source: models.Source{Name: "Economic Times"},
+		URL: "https://economictimes.indiatimes.com/markets/stocks/news/late-et-story-on-markets/articleshow/1.cms", PublishedAt: time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC)})
+	insertTestArticle(t, models.Article{Title: "Morning ET story", Source: models.Source{Name: "Economic Times"},
+		URL: "https://economictimes.indiatimes.com/markets/stocks/news/morning-et-story-on-markets/articleshow/2.cms", PublishedAt: time.Date(2026, 10, 18, 4, 0, 0, 0, time.UTC)})
+	insertTestArticle(t, models.Article{Title: "Mint story", URL: "https://www.livemint.com/market/mint-story-about-the-market-today-1.html",
+		PublishedAt: time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)})
+
+	// Articles without a section, stored as '' and as NULL, share one bucket,
+	// and undated articles are left out of the days
+	insertTestArticle(t, models.Article{Title: "Blank section", URL: "https://example.com/", PublishedAt: time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)})
+	undated, err := InsertArticle(models.Article{Title: "Undated", URL: "https://example.com/undated", Source: models.Source{Name: "Livemint"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE articles SET section = NULL WHERE id = ?`, undated); err != nil {
+		t.Fatal(err)
+	}
+
+	facets, err := GetFacets(ArticleFilter{}, ist)
+	if err != nil {
+		t.Fatalf("GetFacets() error = %v", err)
+	}
+	wantSources := []FacetCount{{"Livemint", 3}, {"Economic Times", 2}}
+	if !reflect.DeepEqual(facets.Sources, wantSources) {
+		t.Errorf("sources = %v, want %v", facets.Sources, wantSources)
+	}
+	wantDays := []FacetCount{{"2026-10-18", 2}, {"2026-10-17", 2}}
+	if !reflect.DeepEqual(facets.Days, wantDays) {
+		t.Errorf("days = %v, want %v", facets.Days, wantDays)
+	}
+	wantSections := []FacetCount{{"", 2}, {"markets/stocks", 2}, {"market", 1}}
+	if !reflect.DeepEqual(facets.Sections, wantSections) {
+		t.Errorf("sections = %v, want %v", facets.Sections, wantSections)
+	}
+
+	// Counts follow the filter
+	facets, err = GetFacets(ArticleFilter{Sources: []string{"Economic Times"}}, ist)
+	if err != nil {
+		t.Fatalf("GetFacets() error = %v", err)
+	}
+	if want := []FacetCount{{"Economic Times", 2}}; !reflect.DeepEqual(facets.Sources, want) {
+		t.Errorf("filtered sources = %v, want %v", facets.Sources, want)
+	}
+	if want := []FacetCount{{"2026-10-18", 2}}; !reflect.DeepEqual(facets.Days, want) {
+		t.Errorf("filtered days = %v, want %v", facets.Days, want)
+	}
+}
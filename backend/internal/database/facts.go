@@ -0,0 +1,107 @@
+package database
+
+import (
+	"fmt"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func createFactTables() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS article_facts (
+			article_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			symbol TEXT NOT NULL DEFAULT '',
+			kind TEXT NOT NULL,
+			label TEXT NOT NULL DEFAULT '',
+			period TEXT NOT NULL DEFAULT '',
+			value REAL NOT NULL,
+			amount REAL NOT NULL,
+			scale TEXT NOT NULL DEFAULT '',
+			unit TEXT NOT NULL,
+			text TEXT NOT NULL,
+			context TEXT NOT NULL DEFAULT '',
+			PRIMARY KEY (article_id, position)
+		);
+		CREATE INDEX IF NOT EXISTS idx_article_facts_symbol ON article_facts(symbol, kind);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// The extractor version an article's facts were found with
+	return ensureColumn("articles", "facts_version", "TEXT")
+}
+
+// SaveArticleFacts replaces the numeric facts of an article and records the
+// extractor version they were found with
+func SaveArticleFacts(articleID int64, facts []models.ArticleFact, version string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM article_facts WHERE article_id = ?`, articleID); err != nil {
+		return fmt.Errorf("failed to clear facts: %v", err)
+	}
+	for i, f := range facts {
+		_, err := tx.Exec(`
+			INSERT INTO article_facts (article_id, position, symbol, kind, label, period, value, amount, scale, unit, text, context)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			articleID, i, f.Symbol, f.Kind, f.Label, f.Period, f.Value, f.Amount, f.Scale, f.Unit, f.Text, f.Context)
+		if err != nil {
+			return fmt.Errorf("failed to store fact %q: %v", f.Text, err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE articles SET facts_version = ? WHERE id = ?`, version, articleID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetArticlesToExtract returns up to limit articles, with their linked
+// companies, whose facts were not yet extracted with the given version
+func GetArticlesToExtract(version string, limit int) ([]models.Article, error) {
+	rows, err := db.Query(`
+		SELECT `+articleColumns+` FROM articles
+		WHERE COALESCE(facts_version, '') != ?
+		ORDER BY id LIMIT ?`, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []models.Article
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return articles, attachTickers(articles)
+}
+
+// attachFacts fills in the numeric facts of an article in text order
+func attachFacts(article *models.Article) error {
+	rows, err := db.Query(`
+		SELECT symbol, kind, label, period, value, amount, scale, unit, text, context
+		FROM article_facts WHERE article_id = ? ORDER BY position`, article.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load facts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f models.ArticleFact
+		if err := rows.Scan(&f.Symbol, &f.Kind, &f.Label, &f.Period, &f.Value, &f.Amount, &f.Scale, &f.Unit, &f.Text, &f.Context); err != nil {
+			return err
+		}
+		article.Facts = append(article.Facts, f)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"stock-news-aggregator/internal/search"
+)
+
+// likeEscaper escapes LIKE wildcards so query terms match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePattern returns a LIKE pattern matching value anywhere in a column
+func likePattern(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
+}
+
+// compileQuery turns a parsed search query into a parameterized SQL condition
+// over the articles table
+func compileQuery(node search.Node) (string, []interface{}, error) {
+	switch n := node.(type) {
+	case *search.And:
+		return compileGroup(n.Children, " AND ")
+	case *search.Or:
+		return compileGroup(n.Children, " OR ")
+	case *search.Not:
+		clause, args, err := compileQuery(n.Child)
+		if err != nil {
+			return "", nil, err
+		}
+		return `NOT ` + clause, args, nil
+	case *search.DateRange:
+		var conditions []string
+		var args []interface{}
+		if !n.From.IsZero() {
+			conditions = append(conditions, publishedAt+` >= ?`)
+			args = append(args, sqliteTime(n.From))
+		}
+		if !n.To.IsZero() {
+			conditions = append(conditions, publishedAt+` < ?`)
+			args = append(args, sqliteTime(n.To))
+		}
+		if len(conditions) == 0 {
+			return `1`, nil, nil
+		}
+		return `(` + strings.Join(conditions, " AND ") + `)`, args, nil
+	case *search.Term:
+		clause, args := compileTerm(n)
+		return clause, args, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported query node %T", node)
+	}
+}
+
+func compileGroup(children []search.Node, sep string) (string, []interface{}, error) {
+	clauses := make([]string, len(children))
+	var args []interface{}
+	for i, child := range children {
+		clause, childArgs, err := compileQuery(child)
+		if err != nil {
+			return "", nil, err
+		}
+		clauses[i] = clause
+		args = append(args, childArgs...)
+	}
+	return `(` + strings.Join(clauses, sep) + `)`, args, nil
+}
+
+func compileTerm(term *search.Term) (string, []interface{}) {
+	pattern := likePattern(term.Value)
+	switch term.Field {
+	case search.FieldSource:
+		return `source = ?`, []interface{}{term.Value}
+	case search.FieldTitle:
+		return `title LIKE ? ESCAPE '\'`, []interface{}{pattern}
+	case search.FieldTicker:
+		return `id IN (SELECT article_id FROM article_tickers WHERE symbol = ?)`, []interface{}{term.Value}
+	default:
+		// Free text matches anywhere in the article text
+		return `(title LIKE ? ESCAPE '\' OR COALESCE(description, '') LIKE ? ESCAPE '\' OR COALESCE(content, '') LIKE ? ESCAPE '\')`,
+			[]interface{}{pattern, pattern, pattern}
+	}
+}
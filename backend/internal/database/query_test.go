@@ -0,0 +1,49 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/search"
+)
+
+// unknownNode is a query node the compiler does not know
+type unknownNode struct{}
+
+func (unknownNode) String() string { return "unknown" }
+
+func TestCompileQueryUnknownNode(t *testing.T) {
+	query := &search.And{Children: []search.Node{&search.Term{Value: "a"}, &search.Not{Child: unknownNode{}}}}
+	if _, _, err := compileQuery(query); err == nil {
+		t.Fatal("compileQuery() with an unknown node returned no error")
+	}
+
+	openTestDB(t)
+	if _, _, err := GetArticles(1, 10, ArticleFilter{Query: query}); err == nil {
+		t.Error("GetArticles() with an unknown node returned no error")
+	}
+}
+
+func TestLargestQueriesRun(t *testing.T) {
+	openTestDB(t)
+	insertTestArticle(t, models.Article{Title: "a b"})
+
+	// The biggest queries the parser accepts stay within SQLite's limits
+	for _, q := range []string{
+		strings.Repeat("(", 20) + "a" + strings.Repeat(")", 20),
+		strings.Repeat("NOT ", 20) + "a",
+		strings.TrimSpace(strings.Repeat("-(a OR ", 10) + "b" + strings.Repeat(")", 10)),
+		strings.TrimSpace(strings.Repeat("a OR ", 99) + "b"),
+		strings.TrimSpace(strings.Repeat("title:a ", 100)),
+	} {
+		node, err := search.Parse(q, search.Options{Now: time.Now()})
+		if err != nil {
+			t.Fatalf("Parse(%.30q...) error = %v", q, err)
+		}
+		if _, _, err := GetArticles(1, 10, ArticleFilter{Query: node, Sort: SortRelevance}); err != nil {
+			t.Errorf("GetArticles(%.30q...) error = %v", q, err)
+		}
+	}
+}
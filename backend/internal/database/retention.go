@@ -0,0 +1,325 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy describes how long articles from a source are kept.
+// A zero day count disables that step.
+type RetentionPolicy struct {
+	Source      string `json:"source"`      // "*" applies to sources without their own policy
+	ContentDays int    `json:"contentDays"` // strip content after this many days, keeping metadata
+	ArchiveDays int    `json:"archiveDays"` // move the row to the archive database after this many days
+}
+
+// RetentionRun records what a single retention pass removed for one source
+type RetentionRun struct {
+	ID              int64     `json:"id"`
+	RanAt           time.Time `json:"ranAt"`
+	Source          string    `json:"source"`
+	ContentStripped int64     `json:"contentStripped"`
+	Archived        int64     `json:"archived"`
+}
+
+// SourceStats holds row counts for a single source
+type SourceStats struct {
+	Source        string `json:"source"`
+	Articles      int64  `json:"articles"`
+	WithContent   int64  `json:"withContent"`
+	OldestPublish string `json:"oldestPublishedAt"`
+	NewestPublish string `json:"newestPublishedAt"`
+}
+
+// DatabaseStats reports the size of the database and its contents
+type DatabaseStats struct {
+	FileSizeBytes int64         `json:"fileSizeBytes"`
+	PageCount     int64         `json:"pageCount"`
+	PageSize      int64         `json:"pageSize"`
+	FreePages     int64         `json:"freePages"`
+	TotalArticles int64         `json:"totalArticles"`
+	Sources       []SourceStats `json:"sources"`
+}
+
+// articleDependents are tables keyed by article_id whose rows go with an
+// archived article
+var articleDependents = []string{"alerts", "article_tickers", "article_events", "article_facts", "summaries"}
+
+// articleAge is the timestamp retention cutoffs are compared against. Some
+// scrapers leave published_at as the zero time, so fall back to created_at.
+const articleAge = `datetime(CASE WHEN published_at IS NULL OR datetime(published_at) < '1971-01-01' THEN created_at ELSE published_at END)`
+
+// policyFor returns the policy that applies to a source, falling back to "*"
+func policyFor(policies []RetentionPolicy, source string) (RetentionPolicy, bool) {
+	var fallback *RetentionPolicy
+	for i := range policies {
+		if policies[i].Source == source {
+			return policies[i], true
+		}
+		if policies[i].Source == "*" {
+			fallback = &policies[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return RetentionPolicy{}, false
+}
+
+// cutoff formats the moment `days` days before now the way SQLite's datetime() does
+func cutoff(now time.Time, days int) string {
+	return now.AddDate(0, 0, -days).UTC().Format("2006-01-02 15:04:05")
+}
+
+// archiveColumns adds any columns of the live articles table that the
+// archive's lacks, such as ones added since the archive was created, and
+// returns the names of the live columns
+func archiveColumns(ctx context.Context, conn *sql.Conn) ([]string, error) {
+	tableInfo := func(schema string) ([]string, map[string]string, error) {
+		rows, err := conn.QueryContext(ctx, `SELECT name, type FROM pragma_table_info('articles', ?)`, schema)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer rows.Close()
+
+		var names []string
+		types := make(map[string]string)
+		for rows.Next() {
+			var name, columnType string
+			if err := rows.Scan(&name, &columnType); err != nil {
+				return nil, nil, err
+			}
+			names = append(names, name)
+			types[name] = columnType
+		}
+		return names, types, rows.Err()
+	}
+
+	live, types, err := tableInfo("main")
+	if err != nil {
+		return nil, err
+	}
+	_, archived, err := tableInfo("archive")
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range live {
+		if _, ok := archived[column]; ok {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE archive.articles ADD COLUMN %s %s`, column, types[column])); err != nil {
+			return nil, err
+		}
+	}
+	return live, nil
+}
+
+// ApplyRetention strips content and archives old rows according to the given
+// policies. Archived rows are copied into archivePath before being deleted.
+// The database is vacuumed afterwards if anything changed.
+func ApplyRetention(policies []RetentionPolicy, archivePath string, now time.Time) ([]RetentionRun, error) {
+	ctx := context.Background()
+
+	// ATTACH is per connection, so keep the whole pass on a single one
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS archive`, archivePath); err != nil {
+		return nil, fmt.Errorf("failed to attach archive database: %v", err)
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE archive`)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS archive.articles (
+			id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL,
+			url TEXT UNIQUE NOT NULL,
+			source TEXT NOT NULL,
+			content TEXT,
+			description TEXT,
+			published_at DATETIME,
+			created_at DATETIME,
+			last_scraped_at DATETIME,
+			archived_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive table: %v", err)
+	}
+	columns, err := archiveColumns(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update archive table: %v", err)
+	}
+	columnList := strings.Join(columns, ", ")
+
+	rows, err := conn.QueryContext(ctx, `SELECT DISTINCT source FROM articles ORDER BY source`)
+	if err != nil {
+		return nil, err
+	}
+	var sources []string
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	rows.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var runs []RetentionRun
+	var changed bool
+	for _, source := range sources {
+		policy, ok := policyFor(policies, source)
+		if !ok {
+			continue
+		}
+		run := RetentionRun{RanAt: now, Source: source}
+
+		if policy.ContentDays > 0 {
+			res, err := tx.ExecContext(ctx, `
+				UPDATE articles SET content = NULL
+				WHERE source = ? AND content IS NOT NULL AND content != ''
+				AND `+articleAge+` < ?`,
+				source, cutoff(now, policy.ContentDays))
+			if err != nil {
+				return nil, fmt.Errorf("failed to strip content for %s: %v", source, err)
+			}
+			run.ContentStripped, _ = res.RowsAffected()
+		}
+
+		if policy.ArchiveDays > 0 {
+			where := ` WHERE source = ? AND ` + articleAge + ` < ?`
+			args := []interface{}{source, cutoff(now, policy.ArchiveDays)}
+
+			_, err := tx.ExecContext(ctx, `
+				INSERT OR REPLACE INTO archive.articles (`+columnList+`)
+				SELECT `+columnList+` FROM main.articles`+where, args...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to archive articles for %s: %v", source, err)
+			}
+
+			res, err := tx.ExecContext(ctx, `DELETE FROM main.articles`+where, args...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete archived articles for %s: %v", source, err)
+			}
+			run.Archived, _ = res.RowsAffected()
+		}
+
+		if run.ContentStripped == 0 && run.Archived == 0 {
+			continue
+		}
+		changed = true
+
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO retention_runs (ran_at, source, content_stripped, archived)
+			VALUES (?, ?, ?, ?)`,
+			now, run.Source, run.ContentStripped, run.Archived)
+		if err != nil {
+			return nil, err
+		}
+		run.ID, _ = res.LastInsertId()
+		runs = append(runs, run)
+	}
+
+	if changed {
+		for _, table := range articleDependents {
+			_, err := tx.ExecContext(ctx, `DELETE FROM main.`+table+` WHERE article_id NOT IN (SELECT id FROM main.articles)`)
+			if err != nil {
+				return nil, fmt.Errorf("failed to clean up %s: %v", table, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if changed {
+		if _, err := conn.ExecContext(ctx, `VACUUM`); err != nil {
+			return runs, fmt.Errorf("failed to vacuum database: %v", err)
+		}
+	}
+
+	return runs, nil
+}
+
+// GetRetentionRuns returns the most recent retention runs, newest first
+func GetRetentionRuns(limit int) ([]RetentionRun, error) {
+	rows, err := db.Query(`
+		SELECT id, ran_at, source, content_stripped, archived
+		FROM retention_runs
+		ORDER BY ran_at DESC, id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []RetentionRun
+	for rows.Next() {
+		var run RetentionRun
+		if err := rows.Scan(&run.ID, &run.RanAt, &run.Source, &run.ContentStripped, &run.Archived); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// GetDatabaseStats reports the database size and per-source row counts
+func GetDatabaseStats() (*DatabaseStats, error) {
+	stats := &DatabaseStats{}
+
+	if info, err := os.Stat(dbFile); err == nil {
+		stats.FileSizeBytes = info.Size()
+	}
+
+	if err := db.QueryRow(`PRAGMA page_count`).Scan(&stats.PageCount); err != nil {
+		return nil, err
+	}
+	if err := db.QueryRow(`PRAGMA page_size`).Scan(&stats.PageSize); err != nil {
+		return nil, err
+	}
+	if err := db.QueryRow(`PRAGMA freelist_count`).Scan(&stats.FreePages); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT source,
+			COUNT(*),
+			SUM(CASE WHEN content IS NOT NULL AND content != '' THEN 1 ELSE 0 END),
+			COALESCE(MIN(datetime(published_at)), ''),
+			COALESCE(MAX(datetime(published_at)), '')
+		FROM articles
+		GROUP BY source
+		ORDER BY source`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s SourceStats
+		if err := rows.Scan(&s.Source, &s.Articles, &s.WithContent, &s.OldestPublish, &s.NewestPublish); err != nil {
+			return nil, err
+		}
+		stats.TotalArticles += s.Articles
+		stats.Sources = append(stats.Sources, s)
+	}
+
+	return stats, rows.Err()
+}
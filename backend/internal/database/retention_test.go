@@ -0,0 +1,166 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func TestApplyRetention(t *testing.T) {
+	openTestDB(t)
+	now := time.Now()
+	days := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+
+	content := "Full article text."
+	fresh := insertTestArticle(t, models.Article{Title: "fresh", Content: content, PublishedAt: days(29)})
+	pastContent := insertTestArticle(t, models.Article{Title: "past-content", Content: content, PublishedAt: days(30).Add(-time.Hour)})
+	pastArchive := insertTestArticle(t, models.Article{Title: "past-archive", Content: content, ImageURL: "https://img.example.com/a.jpg",
+		Section: "markets/stocks", PublishedAt: days(180).Add(-time.Hour)})
+	undated := insertTestArticle(t, models.Article{Title: "undated", Content: content, PublishedAt: time.Time{}})
+	other := insertTestArticle(t, models.Article{Title: "other", Source: models.Source{Name: "Economic Times"}, Content: content, PublishedAt: days(60)})
+
+	// Undated articles are aged by when they were stored
+	if _, err := db.Exec(`UPDATE articles SET published_at = NULL, created_at = ? WHERE id = ?`, sqliteTime(days(200)), undated); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows in other tables that go with the archived article
+	if err := SaveArticleTickers(pastArchive, []models.ArticleTicker{{Symbol: "TCS", Name: "TCS", Confidence: 1, Mentions: 1}}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveArticleEvents(pastArchive, []models.ArticleEvent{{Type: "earnings", Score: 1}}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveArticleFacts(pastArchive, []models.ArticleFact{{Kind: "percent", Value: 4.5, Amount: 4.5, Unit: "%", Text: "4.5%"}}, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSummary(ArticleSummaryKey(pastArchive, "1", ""), CachedSummary{Summary: "Summary."}); err != nil {
+		t.Fatal(err)
+	}
+	if err := InsertAlerts(1, []int64{pastArchive, fresh}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveArticleSentiment(pastArchive, models.ArticleSentiment{Score: 0.4, Label: "positive"}, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	policies := []RetentionPolicy{
+		{Source: "*", ContentDays: 90, ArchiveDays: 0},
+		{Source: "Livemint", ContentDays: 30, ArchiveDays: 180},
+	}
+	// An archive created before articles had images, sections and sentiment
+	// gains the columns
+	archivePath := filepath.Join(t.TempDir(), "archive.db")
+	archive, err := sql.Open("sqlite3", archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	if _, err := archive.Exec(`
+		CREATE TABLE articles (
+			id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL,
+			url TEXT UNIQUE NOT NULL,
+			source TEXT NOT NULL,
+			content TEXT,
+			description TEXT,
+			published_at DATETIME,
+			created_at DATETIME,
+			last_scraped_at DATETIME,
+			archived_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := ApplyRetention(policies, archivePath, now)
+	if err != nil {
+		t.Fatalf("ApplyRetention() error = %v", err)
+	}
+
+	// Economic Times is within its 90 days, so only Livemint changed. Rows
+	// past the archive cutoff are past the content cutoff too.
+	if len(runs) != 1 || runs[0].Source != "Livemint" || runs[0].ContentStripped != 3 || runs[0].Archived != 2 {
+		t.Fatalf("runs = %+v, want Livemint with 3 stripped and 2 archived", runs)
+	}
+	logged, err := GetRetentionRuns(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 1 || logged[0].ID != runs[0].ID || logged[0].Archived != 2 {
+		t.Errorf("logged runs = %+v, want the returned run", logged)
+	}
+
+	contentOf := func(id int64) (string, bool) {
+		var content sql.NullString
+		err := db.QueryRow(`SELECT content FROM articles WHERE id = ?`, id).Scan(&content)
+		if err == sql.ErrNoRows {
+			return "", false
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return content.String, true
+	}
+	for id, want := range map[int64]string{fresh: content, pastContent: "", other: content} {
+		got, ok := contentOf(id)
+		if !ok || got != want {
+			t.Errorf("article %d content = %q (stored %v), want %q", id, got, ok, want)
+		}
+	}
+	for _, id := range []int64{pastArchive, undated} {
+		if _, ok := contentOf(id); ok {
+			t.Errorf("archived article %d is still stored", id)
+		}
+	}
+
+	var archived []int64
+	rows, err := archive.Query(`SELECT id FROM articles ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		archived = append(archived, id)
+	}
+	rows.Close()
+	if want := []int64{pastArchive, undated}; !reflect.DeepEqual(archived, want) {
+		t.Errorf("archive holds %v, want %v", archived, want)
+	}
+	var imageURL, section, label string
+	var score float64
+	err = archive.QueryRow(`SELECT image_url, section, sentiment_score, sentiment_label FROM articles WHERE id = ?`, pastArchive).
+		Scan(&imageURL, &section, &score, &label)
+	if err != nil {
+		t.Fatalf("reading the archived article: %v", err)
+	}
+	if imageURL != "https://img.example.com/a.jpg" || section != "markets/stocks" || score != 0.4 || label != "positive" {
+		t.Errorf("archived image %q, section %q, sentiment %v %q, want them kept", imageURL, section, score, label)
+	}
+
+	for _, table := range articleDependents {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE article_id = ?`, pastArchive).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%s still has %d rows for the archived article", table, count)
+		}
+	}
+	var alerts int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM alerts WHERE article_id = ?`, fresh).Scan(&alerts); err != nil || alerts != 1 {
+		t.Errorf("alert for the kept article: count %d, error %v, want 1", alerts, err)
+	}
+
+	// A second pass has nothing left to do
+	runs, err = ApplyRetention(policies, archivePath, now)
+	if err != nil || len(runs) != 0 {
+		t.Errorf("second pass = %+v, %v, want no runs", runs, err)
+	}
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func createSentimentColumns() error {
+	for column, definition := range map[string]string{
+		"sentiment_score":   "REAL",
+		"sentiment_label":   "TEXT",
+		"sentiment_version": "TEXT",
+	} {
+		if err := ensureColumn("articles", column, definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveArticleSentiment stores an article's sentiment and the lexicon version
+// it was scored with
+func SaveArticleSentiment(articleID int64, sentiment models.ArticleSentiment, version string) error {
+	_, err := db.Exec(`
+		UPDATE articles SET sentiment_score = ?, sentiment_label = ?, sentiment_version = ?
+		WHERE id = ?`,
+		sentiment.Score, sentiment.Label, version, articleID)
+	if err != nil {
+		return fmt.Errorf("failed to store sentiment: %v", err)
+	}
+	return nil
+}
+
+// GetArticlesToScore returns up to limit articles not yet scored with the
+// given lexicon version
+func GetArticlesToScore(version string, limit int) ([]models.Article, error) {
+	rows, err := db.Query(`
+		SELECT `+articleColumns+` FROM articles
+		WHERE COALESCE(sentiment_version, '') != ?
+		ORDER BY id LIMIT ?`, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []models.Article
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+	return articles, rows.Err()
+}
+
+// DailySentiment is the sentiment of one day's articles
+type DailySentiment struct {
+	Date     string  `json:"date"` // YYYY-MM-DD in the requested time zone
+	Articles int     `json:"articles"`
+	Average  float64 `json:"average"`
+	Positive int     `json:"positive"`
+	Negative int     `json:"negative"`
+	Neutral  int     `json:"neutral"`
+}
+
+// SourceSentiment is the daily sentiment of one source's articles
+type SourceSentiment struct {
+	Source string           `json:"source"`
+	Days   []DailySentiment `json:"days"`
+}
+
+// sentimentDay buckets articles by calendar day in a time zone, given as a
+// "+N seconds" modifier
+const sentimentDay = `date(` + articleAge + `, ?)`
+
+const sentimentAggregates = `COUNT(*), AVG(sentiment_score),
+	SUM(sentiment_label = 'positive'), SUM(sentiment_label = 'negative'), SUM(sentiment_label = 'neutral')`
+
+// dayOffset is the SQLite modifier that shifts UTC timestamps into loc
+func dayOffset(loc *time.Location) string {
+	_, offset := time.Now().In(loc).Zone()
+	return fmt.Sprintf("%+d seconds", offset)
+}
+
+func scanDailySentiment(row rowScanner, extra ...interface{}) (DailySentiment, error) {
+	var day DailySentiment
+	dest := append(extra, &day.Date, &day.Articles, &day.Average, &day.Positive, &day.Negative, &day.Neutral)
+	if err := row.Scan(dest...); err != nil {
+		return day, err
+	}
+	day.Average = math.Round(day.Average*1000) / 1000
+	return day, nil
+}
+
+// GetTickerSentiment aggregates the sentiment of the articles linked to a
+// company by day, oldest first, counting articles published since the given
+// time. Days are calendar days in loc.
+func GetTickerSentiment(symbol string, since time.Time, loc *time.Location) ([]DailySentiment, error) {
+	rows, err := db.Query(`
+		SELECT `+sentimentDay+` AS day, `+sentimentAggregates+`
+		FROM articles
+		WHERE sentiment_score IS NOT NULL
+			AND id IN (SELECT article_id FROM article_tickers WHERE symbol = ?)
+			AND `+articleAge+` >= ?
+		GROUP BY day ORDER BY day`,
+		dayOffset(loc), symbol, sqliteTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate sentiment for %s: %v", symbol, err)
+	}
+	defer rows.Close()
+
+	days := []DailySentiment{}
+	for rows.Next() {
+		day, err := scanDailySentiment(rows)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
+
+// GetSourceSentiment aggregates article sentiment by source and day, oldest
+// day first, counting articles published since the given time
+func GetSourceSentiment(since time.Time, loc *time.Location) ([]SourceSentiment, error) {
+	rows, err := db.Query(`
+		SELECT source, `+sentimentDay+` AS day, `+sentimentAggregates+`
+		FROM articles
+		WHERE sentiment_score IS NOT NULL AND `+articleAge+` >= ?
+		GROUP BY source, day ORDER BY source, day`,
+		dayOffset(loc), sqliteTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate sentiment by source: %v", err)
+	}
+	defer rows.Close()
+
+	sources := []SourceSentiment{}
+	for rows.Next() {
+		var source string
+		day, err := scanDailySentiment(rows, &source)
+		if err != nil {
+			return nil, err
+		}
+		if len(sources) == 0 || sources[len(sources)-1].Source != source {
+			sources = append(sources, SourceSentiment{Source: source})
+		}
+		last := &sources[len(sources)-1]
+		last.Days = append(last.Days, day)
+	}
+	return sources, rows.Err()
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func createSummaryTables() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS summaries (
+			subject TEXT NOT NULL,
+			article_id INTEGER,
+			url TEXT NOT NULL DEFAULT '',
+			version TEXT NOT NULL,
+			params TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (subject, version, params)
+		);
+		CREATE INDEX IF NOT EXISTS idx_summaries_article ON summaries(article_id);
+	`)
+	if err != nil {
+		return err
+	}
+	if err := ensureColumn("summaries", "indices", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return ensureColumn("summaries", "sentences", "TEXT NOT NULL DEFAULT ''")
+}
+
+// SummaryKey identifies a cached summary: what was summarized, by which
+// summarizer version and with which parameters. Subject is "article:<id>"
+// for stored articles, "text:<sha256>" for submitted text and the canonical
+// URL otherwise.
+type SummaryKey struct {
+	Subject   string
+	ArticleID int64 // 0 when the page is not a stored article
+	URL       string
+	Version   string
+	Params    string
+}
+
+// CachedSummary is a stored summary
+type CachedSummary struct {
+	Summary   string
+	Indices   []int    // positions of the summary's sentences in the source text
+	Sentences []string // the source text's sentences at those positions
+	CreatedAt time.Time
+}
+
+// GetSummary returns a cached summary, or ErrNotFound
+func GetSummary(key SummaryKey) (*CachedSummary, error) {
+	var cached CachedSummary
+	var indices, sentences string
+	err := db.QueryRow(`
+		SELECT summary, indices, sentences, created_at FROM summaries
+		WHERE subject = ? AND version = ? AND params = ?`,
+		key.Subject, key.Version, key.Params).Scan(&cached.Summary, &indices, &sentences, &cached.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load summary: %v", err)
+	}
+	for _, field := range strings.Split(indices, ",") {
+		if i, err := strconv.Atoi(field); err == nil {
+			cached.Indices = append(cached.Indices, i)
+		}
+	}
+	if sentences != "" {
+		if err := json.Unmarshal([]byte(sentences), &cached.Sentences); err != nil {
+			return nil, fmt.Errorf("failed to decode summary sentences: %v", err)
+		}
+	}
+	return &cached, nil
+}
+
+// SaveSummary stores a summary with the positions and text of its source
+// sentences, replacing any with the same key
+func SaveSummary(key SummaryKey, summary CachedSummary) error {
+	var articleID interface{}
+	if key.ArticleID != 0 {
+		articleID = key.ArticleID
+	}
+	fields := make([]string, len(summary.Indices))
+	for i, index := range summary.Indices {
+		fields[i] = strconv.Itoa(index)
+	}
+	var sentences []byte
+	if len(summary.Sentences) > 0 {
+		sentences, _ = json.Marshal(summary.Sentences)
+	}
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO summaries (subject, article_id, url, version, params, summary, indices, sentences, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		key.Subject, articleID, key.URL, key.Version, key.Params, summary.Summary,
+		strings.Join(fields, ","), string(sentences), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to store summary: %v", err)
+	}
+	return nil
+}
+
+// DeleteStaleSummaries removes the summaries made by any other summarizer
+// version than the given one
+func DeleteStaleSummaries(version string) (int64, error) {
+	res, err := db.Exec(`DELETE FROM summaries WHERE version != ?`, version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale summaries: %v", err)
+	}
+	return res.RowsAffected()
+}
+
+// DeleteUnlinkedSummaries removes the summaries of submitted text and of
+// pages that are not stored articles made before cutoff. Article summaries
+// go when retention archives their article.
+func DeleteUnlinkedSummaries(cutoff time.Time) (int64, error) {
+	res, err := db.Exec(`DELETE FROM summaries WHERE article_id IS NULL AND datetime(created_at) < ?`, sqliteTime(cutoff))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old summaries: %v", err)
+	}
+	return res.RowsAffected()
+}
+
+// GetArticleIDByURL returns the ID of the article stored under any of the
+// given URLs, or ErrNotFound
+func GetArticleIDByURL(urls ...string) (int64, error) {
+	for _, url := range urls {
+		var id int64
+		err := db.QueryRow(`SELECT id FROM articles WHERE url = ?`, url).Scan(&id)
+		if err == nil {
+			return id, nil
+		}
+		if err != sql.ErrNoRows {
+			return 0, err
+		}
+	}
+	return 0, ErrNotFound
+}
+
+// articleSubject is the summary subject of a stored article
+func articleSubject(id int64) string {
+	return "article:" + strconv.FormatInt(id, 10)
+}
+
+// ArticleSummaryKey keys the summary of a stored article
+func ArticleSummaryKey(id int64, version, params string) SummaryKey {
+	return SummaryKey{Subject: articleSubject(id), ArticleID: id, Version: version, Params: params}
+}
+
+// GetArticleSummaries returns the summaries made with the given version and
+// parameters for any of the articles, by article ID
+func GetArticleSummaries(ids []int64, version, params string) (map[int64]string, error) {
+	summaries := make(map[int64]string)
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := []interface{}{version, params}
+	for _, id := range ids {
+		args = append(args, articleSubject(id))
+	}
+	rows, err := db.Query(`
+		SELECT article_id, summary FROM summaries
+		WHERE version = ? AND params = ? AND subject IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load summaries: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var summary string
+		if err := rows.Scan(&id, &summary); err != nil {
+			return nil, err
+		}
+		summaries[id] = summary
+	}
+	return summaries, rows.Err()
+}
+
+// GetArticleIDsToSummarize returns the newest articles with stored content
+// that have no summary for the given version and parameters yet
+func GetArticleIDsToSummarize(version, params string, limit int) ([]int64, error) {
+	rows, err := db.Query(`
+		SELECT id FROM articles a
+		WHERE COALESCE(content, '') != ''
+		AND NOT EXISTS (
+			SELECT 1 FROM summaries s
+			WHERE s.subject = 'article:' || a.id AND s.version = ? AND s.params = ?
+		)
+		ORDER BY id DESC LIMIT ?`, version, params, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func TestGetArticleIDsToSummarize(t *testing.T) {
+	openTestDB(t)
+	summarized := insertTestArticle(t, models.Article{Title: "summarized", Content: "Profit rose."})
+	outdated := insertTestArticle(t, models.Article{Title: "outdated", Content: "Profit rose."})
+	otherParams := insertTestArticle(t, models.Article{Title: "other-params", Content: "Profit rose."})
+	insertTestArticle(t, models.Article{Title: "description-only", Description: "Profit rose."})
+	fresh := insertTestArticle(t, models.Article{Title: "fresh", Content: "Profit rose."})
+
+	save := func(id int64, version, params string) {
+		t.Helper()
+		if err := SaveSummary(ArticleSummaryKey(id, version, params), CachedSummary{Summary: "Profit rose.", Indices: []int{0}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save(summarized, "2", "a")
+	save(outdated, "1", "a")
+	save(otherParams, "2", "b")
+
+	// Newest first, skipping articles without content and those with a
+	// summary for this version and these parameters
+	ids, err := GetArticleIDsToSummarize("2", "a", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{fresh, otherParams, outdated}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("GetArticleIDsToSummarize() = %v, want %v", ids, want)
+	}
+
+	ids, err = GetArticleIDsToSummarize("2", "a", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{fresh}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("GetArticleIDsToSummarize() with limit 1 = %v, want %v", ids, want)
+	}
+}
+
+func TestGetArticleSummaries(t *testing.T) {
+	openTestDB(t)
+	first := insertTestArticle(t, models.Article{Title: "first", Content: "Profit rose."})
+	second := insertTestArticle(t, models.Article{Title: "second", Content: "Sales fell."})
+	third := insertTestArticle(t, models.Article{Title: "third", Content: "Shares were flat."})
+
+	for _, key := range []SummaryKey{
+		ArticleSummaryKey(first, "2", "a"),
+		ArticleSummaryKey(second, "2", "b"),
+		ArticleSummaryKey(third, "1", "a"),
+	} {
+		if err := SaveSummary(key, CachedSummary{Summary: "summary of " + key.Subject}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := GetArticleSummaries([]int64{first, second, third}, "2", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int64]string{first: "summary of article:1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetArticleSummaries() = %v, want %v", got, want)
+	}
+
+	got, err = GetArticleSummaries(nil, "2", "a")
+	if err != nil || len(got) != 0 {
+		t.Errorf("GetArticleSummaries(nil) = %v, %v, want an empty map", got, err)
+	}
+}
+
+func TestSaveSummary(t *testing.T) {
+	openTestDB(t)
+	key := SummaryKey{Subject: "text:abc", Version: "2", Params: "a"}
+	saved := CachedSummary{
+		Summary:   "- Profit rose 12%.\n- Margins, however, narrowed.",
+		Indices:   []int{0, 3},
+		Sentences: []string{"Profit rose 12%.", "Margins, however, narrowed."},
+	}
+	if err := SaveSummary(key, saved); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetSummary(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Summary != saved.Summary || !reflect.DeepEqual(got.Indices, saved.Indices) || !reflect.DeepEqual(got.Sentences, saved.Sentences) {
+		t.Errorf("GetSummary() = %+v, want %+v", got, saved)
+	}
+
+	if _, err := GetSummary(SummaryKey{Subject: "text:abc", Version: "1", Params: "a"}); err != ErrNotFound {
+		t.Errorf("GetSummary() for another version error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDeleteUnlinkedSummaries(t *testing.T) {
+	openTestDB(t)
+	id := insertTestArticle(t, models.Article{Title: "stored", Content: "Profit rose."})
+
+	keys := map[string]SummaryKey{
+		"old text":    {Subject: "text:old", Version: "1", Params: "a"},
+		"old page":    {Subject: "https://example.com/page", URL: "https://example.com/page", Version: "1", Params: "a"},
+		"old article": ArticleSummaryKey(id, "1", "a"),
+		"new text":    {Subject: "text:new", Version: "1", Params: "a"},
+	}
+	for name, key := range keys {
+		if err := SaveSummary(key, CachedSummary{Summary: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().AddDate(0, 0, -40)
+	if _, err := db.Exec(`UPDATE summaries SET created_at = ? WHERE summary LIKE 'old %'`, old); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := DeleteUnlinkedSummaries(time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteUnlinkedSummaries() deleted %d, want 2", deleted)
+	}
+	for name, key := range keys {
+		_, err := GetSummary(key)
+		if gone := err == ErrNotFound; gone != (name == "old text" || name == "old page") {
+			t.Errorf("%s: GetSummary() error = %v after deleting", name, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func createTickerTables() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS article_tickers (
+			article_id INTEGER NOT NULL,
+			symbol TEXT NOT NULL,
+			name TEXT NOT NULL,
+			confidence REAL NOT NULL,
+			mentions INTEGER NOT NULL DEFAULT 1,
+			PRIMARY KEY (article_id, symbol)
+		);
+		CREATE INDEX IF NOT EXISTS idx_article_tickers_symbol ON article_tickers(symbol, article_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// The symbol master version an article was last linked with, so a new
+	// master or linker relinks everything
+	return ensureColumn("articles", "tickers_version", "TEXT")
+}
+
+// SaveArticleTickers replaces the companies linked to an article and records
+// the symbol master version they were found with
+func SaveArticleTickers(articleID int64, tickers []models.ArticleTicker, version string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM article_tickers WHERE article_id = ?`, articleID); err != nil {
+		return fmt.Errorf("failed to clear tickers: %v", err)
+	}
+	for _, t := range tickers {
+		_, err := tx.Exec(`
+			INSERT INTO article_tickers (article_id, symbol, name, confidence, mentions)
+			VALUES (?, ?, ?, ?, ?)`,
+			articleID, t.Symbol, t.Name, t.Confidence, t.Mentions)
+		if err != nil {
+			return fmt.Errorf("failed to store ticker %s: %v", t.Symbol, err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE articles SET tickers_version = ? WHERE id = ?`, version, articleID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetArticlesToLink returns up to limit articles not yet linked with the
+// given symbol master version
+func GetArticlesToLink(version string, limit int) ([]models.Article, error) {
+	rows, err := db.Query(`
+		SELECT `+articleColumns+` FROM articles
+		WHERE COALESCE(tickers_version, '') != ?
+		ORDER BY id LIMIT ?`, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []models.Article
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+	return articles, rows.Err()
+}
+
+// attachTickers fills in the linked companies of the given articles, most
+// confident first
+func attachTickers(articles []models.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(articles)), ",")
+	args := make([]interface{}, len(articles))
+	index := make(map[int64]int, len(articles))
+	for i, article := range articles {
+		args[i] = article.ID
+		index[article.ID] = i
+	}
+
+	rows, err := db.Query(`
+		SELECT article_id, symbol, name, confidence, mentions FROM article_tickers
+		WHERE article_id IN (`+placeholders+`)
+		ORDER BY confidence DESC, symbol`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to load tickers: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var articleID int64
+		var t models.ArticleTicker
+		if err := rows.Scan(&articleID, &t.Symbol, &t.Name, &t.Confidence, &t.Mentions); err != nil {
+			return err
+		}
+		i := index[articleID]
+		articles[i].Tickers = append(articles[i].Tickers, t)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,136 @@
+package events
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// titleWeight is how much more a keyword counts in the headline than in the
+// body
+const titleWeight = 2
+
+// Event is an event type an article was classified as
+type Event struct {
+	Type  string
+	Score float64
+}
+
+// keyword is one rule phrase as lower-cased words
+type keyword struct {
+	words   []string
+	rule    int
+	weight  float64
+	exclude bool
+}
+
+// Classifier assigns event types to articles by keyword rules
+type Classifier struct {
+	rules    []Rule
+	defaults float64
+	keywords map[string][]keyword // by first word
+}
+
+// NewClassifier indexes the keywords of a rule set
+func NewClassifier(rules *Rules) *Classifier {
+	c := &Classifier{
+		rules:    rules.Rules,
+		defaults: rules.Threshold,
+		keywords: make(map[string][]keyword),
+	}
+	add := func(text string, k keyword) {
+		k.words = tokenize(text)
+		if len(k.words) > 0 {
+			c.keywords[k.words[0]] = append(c.keywords[k.words[0]], k)
+		}
+	}
+	for i, rule := range rules.Rules {
+		for text, weight := range rule.Keywords {
+			add(text, keyword{rule: i, weight: weight})
+		}
+		for _, text := range rule.Exclude {
+			add(text, keyword{rule: i, exclude: true})
+		}
+	}
+	return c
+}
+
+// Types lists the event types in rule file order
+func (c *Classifier) Types() []string {
+	types := make([]string, len(c.rules))
+	for i, rule := range c.rules {
+		types[i] = rule.Type
+	}
+	return types
+}
+
+// Name returns the display name of an event type
+func (c *Classifier) Name(eventType string) (string, bool) {
+	for _, rule := range c.rules {
+		if rule.Type == eventType {
+			return rule.Name, true
+		}
+	}
+	return "", false
+}
+
+// Classify returns the event types an article matches, in rule file order.
+// Each keyword counts once, at its headline weight if it appears there.
+func (c *Classifier) Classify(title, body string) []Event {
+	scores := make([]float64, len(c.rules))
+	excluded := make([]bool, len(c.rules))
+	seen := make(map[*keyword]bool)
+
+	scan := func(text string, factor float64) {
+		words := tokenize(text)
+		for i := range words {
+			list := c.keywords[words[i]]
+			for j := range list {
+				k := &list[j]
+				if seen[k] || !hasWords(words, i, k.words) {
+					continue
+				}
+				seen[k] = true
+				if k.exclude {
+					excluded[k.rule] = true
+				} else {
+					scores[k.rule] += k.weight * factor
+				}
+			}
+		}
+	}
+	scan(title, titleWeight)
+	scan(body, 1)
+
+	var events []Event
+	for i, rule := range c.rules {
+		threshold := rule.Threshold
+		if threshold <= 0 {
+			threshold = c.defaults
+		}
+		if !excluded[i] && scores[i] >= threshold {
+			events = append(events, Event{Type: rule.Type, Score: math.Round(scores[i]*100) / 100})
+		}
+	}
+	return events
+}
+
+func hasWords(words []string, i int, phrase []string) bool {
+	if i+len(phrase) > len(words) {
+		return false
+	}
+	for j, word := range phrase {
+		if words[i+j] != word {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize lower-cases text and splits it into words, keeping "&" so that
+// "M&A" stays one word
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+	})
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+func defaultClassifier(t *testing.T) *Classifier {
+	t.Helper()
+	rules, _, err := LoadRules("")
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	return NewClassifier(rules)
+}
+
+func TestClassify(t *testing.T) {
+	c := defaultClassifier(t)
+	tests := []struct {
+		title string
+		body  string
+		want  []string
+	}{
+		{"TCS Q1 results: net profit rises 6% YoY", "", []string{"earnings"}},
+		{"ITC fixes record date for final dividend", "", []string{"dividend"}},
+		{"Bajaj Holdings sells stake in Bajaj Finserv via block deal", "", []string{"block_deal"}},
+		{"HDB Financial IPO: GMP, price band and subscription status", "", []string{"ipo"}},
+		{"Jefferies downgrade: target price cut for Paytm", "", []string{"rating_change"}},
+		{"SEBI order bars promoters over insider trading", "", []string{"regulatory"}},
+		{"Infosys CFO resigns; board appoints successor", "", []string{"management_change"}},
+		{"RBI cuts repo rate by 50 bps, lowers inflation forecast", "", []string{"macro"}},
+		{"Tata Motors to acquire Iveco in M&A push", "", []string{"mergers_acquisitions"}},
+		{"Mazagon Dock announces bonus issue and stock split", "", []string{"bonus_split"}},
+		{"Sensex ends flat in choppy trade", "", nil},
+		// One weak body keyword is not enough
+		{"Markets ends flat", "Traders awaited results from the US.", nil},
+		// Body keywords add up
+		{"Wipro update", "Net profit rose while EBITDA margins widened.", []string{"earnings"}},
+		// Exclude phrases veto a type
+		{"Election results spook markets", "", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, event := range c.Classify(tt.title, tt.body) {
+			got = append(got, event.Type)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Classify(%q, %q) = %v, want %v", tt.title, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`{"rules": [
+		{"type": "buyback", "keywords": {"buyback": 2, "tender offer": 1}, "threshold": 3}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+	if rules.Threshold != DefaultThreshold || rules.Rules[0].Name != "buyback" {
+		t.Errorf("defaults not applied: %+v", rules)
+	}
+
+	c := NewClassifier(rules)
+	if events := c.Classify("", "Board approves buyback"); len(events) != 0 {
+		t.Errorf("body mention below rule threshold classified as %v", events)
+	}
+	if events := c.Classify("Board approves buyback", ""); len(events) != 1 || events[0].Score != 4 {
+		t.Errorf("headline mention = %v, want buyback with score 4", events)
+	}
+
+	invalid := []string{
+		`{"rules": []}`,
+		`{"rules": [{"type": "Bad Type", "keywords": {"x": 1}}]}`,
+		`{"rules": [{"type": "a", "keywords": {}}]}`,
+		`{"rules": [{"type": "a", "keywords": {"x": 0}}]}`,
+		`{"rules": [{"type": "a", "keywords": {"x": 1}}, {"type": "a", "keywords": {"y": 1}}]}`,
+		`not json`,
+	}
+	for _, data := range invalid {
+		if _, err := ParseRules([]byte(data)); err == nil {
+			t.Errorf("ParseRules(%s) succeeded, want error", data)
+		}
+	}
+}
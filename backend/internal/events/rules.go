@@ -0,0 +1,94 @@
+package events
+
+import (
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// defaultRules are used when no rule file is configured
+//
+//go:embed rules.json
+var defaultRules []byte
+
+// DefaultThreshold is the score an article needs to be given an event type
+// when the rule file does not set one
+const DefaultThreshold = 2
+
+// Rule describes how to recognize one event type. Keywords are phrases with
+// a weight; a phrase counts double when it appears in the headline. An
+// article matching any exclude phrase is never given the type.
+type Rule struct {
+	Type      string             `json:"type"`
+	Name      string             `json:"name"`
+	Keywords  map[string]float64 `json:"keywords"`
+	Exclude   []string           `json:"exclude,omitempty"`
+	Threshold float64            `json:"threshold,omitempty"` // overrides the rule file's threshold
+}
+
+// Rules is the contents of a rule file
+type Rules struct {
+	Threshold float64 `json:"threshold"`
+	Rules     []Rule  `json:"rules"`
+}
+
+var validType = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// LoadRules reads a rule file, or the built-in rules when path is empty. It
+// also returns a fingerprint of the rules, so stored classifications can be
+// redone when they change.
+func LoadRules(path string) (*Rules, string, error) {
+	data := defaultRules
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, "", fmt.Errorf("failed to read event rules: %v", err)
+		}
+	}
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha1.Sum(data)
+	return rules, hex.EncodeToString(sum[:])[:12], nil
+}
+
+// ParseRules decodes and validates a JSON rule file
+func ParseRules(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid event rules: %v", err)
+	}
+	if rules.Threshold <= 0 {
+		rules.Threshold = DefaultThreshold
+	}
+	if len(rules.Rules) == 0 {
+		return nil, fmt.Errorf("invalid event rules: no rules")
+	}
+
+	seen := make(map[string]bool)
+	for i, rule := range rules.Rules {
+		switch {
+		case !validType.MatchString(rule.Type):
+			return nil, fmt.Errorf("invalid event rule %d: type %q must be lower-case letters, digits and underscores", i, rule.Type)
+		case seen[rule.Type]:
+			return nil, fmt.Errorf("invalid event rule %d: duplicate type %q", i, rule.Type)
+		case len(rule.Keywords) == 0:
+			return nil, fmt.Errorf("invalid event rule %q: no keywords", rule.Type)
+		}
+		for keyword, weight := range rule.Keywords {
+			if len(tokenize(keyword)) == 0 || weight <= 0 {
+				return nil, fmt.Errorf("invalid event rule %q: keyword %q needs words and a positive weight", rule.Type, keyword)
+			}
+		}
+		if rule.Name == "" {
+			rules.Rules[i].Name = rule.Type
+		}
+		seen[rule.Type] = true
+	}
+	return &rules, nil
+}
@@ -0,0 +1,280 @@
+// Package facts pulls numeric facts such as amounts, percentage moves and
+// price targets out of Indian market news
+package facts
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"stock-news-aggregator/internal/sentences"
+)
+
+// Fact kinds
+const (
+	KindAmount      = "amount"
+	KindPercent     = "percent"
+	KindPriceTarget = "price_target"
+)
+
+// Units facts are normalized to
+const (
+	UnitINR     = "INR"
+	UnitUSD     = "USD"
+	UnitPercent = "%"
+)
+
+// Fact is one number found in an article. Value is normalized to the unit,
+// so "₹2,340 crore" has Amount 2340, Scale "crore" and Value 23400000000.
+// Percentages are signed by the direction of the move when it is stated.
+type Fact struct {
+	Kind     string
+	Label    string // what the number measures, such as "net profit"
+	Period   string // reporting period such as "Q2 FY26"
+	Value    float64
+	Amount   float64
+	Scale    string
+	Unit     string
+	Text     string // the number as written
+	Sentence int    // index of the sentence it was found in
+	Context  string // the sentence itself
+}
+
+// scales are the multipliers of Indian and international number words
+var scales = map[string]struct {
+	name  string
+	value float64
+}{
+	"lakh crore": {"lakh crore", 1e12},
+	"lakh cr":    {"lakh crore", 1e12},
+	"crore":      {"crore", 1e7},
+	"cr":         {"crore", 1e7},
+	"lakh":       {"lakh", 1e5},
+	"lac":        {"lakh", 1e5},
+	"million":    {"million", 1e6},
+	"mn":         {"million", 1e6},
+	"billion":    {"billion", 1e9},
+	"bn":         {"billion", 1e9},
+	"trillion":   {"trillion", 1e12},
+	"tn":         {"trillion", 1e12},
+}
+
+var (
+	amountPattern  = regexp.MustCompile(`(?i)(₹|\brs\.?|\binr\b|\bus\$|\$|\busd\b)\s*(\d{1,3}(?:,\d{2,3})+|\d+)(\.\d+)?(?:\s*(lakh crore|lakh cr|crore|cr|lakh|lac|million|mn|billion|bn|trillion|tn)\b)?`)
+	percentPattern = regexp.MustCompile(`(?i)([+-]?\d+(?:\.\d+)?)\s*(%|per\s?cent\b|percent\b)`)
+	periodPattern  = regexp.MustCompile(`(?i)\b(?:(q[1-4]|h[12])\s*(?:fy\s*'?(\d{2,4}))?|fy\s*'?(\d{2,4}))\b`)
+)
+
+// metrics are what an amount or percentage can measure, matched in the few
+// words before it. Longer phrases are listed first.
+var metrics = []string{
+	"revenue from operations", "profit after tax", "net interest income",
+	"net profit", "net loss", "operating profit", "total income", "market cap",
+	"market capitalisation", "order book", "order inflow", "target price",
+	"price target", "share price", "dividend per share",
+	"revenue", "sales", "ebitda", "pat", "profit", "loss", "income", "dividend",
+	"valuation", "stake", "order", "deal", "buyback", "ipo", "margin", "margins",
+	"target", "gmp",
+}
+
+// targetLabels mark an amount as a price target
+var targetLabels = map[string]bool{"target price": true, "price target": true, "target": true}
+
+// directions sign a percentage that follows them
+var directions = map[string]float64{
+	"up": 1, "rise": 1, "rises": 1, "rose": 1, "risen": 1, "gain": 1, "gains": 1, "gained": 1,
+	"jump": 1, "jumps": 1, "jumped": 1, "surge": 1, "surges": 1, "surged": 1, "climb": 1,
+	"climbs": 1, "climbed": 1, "rally": 1, "rallies": 1, "rallied": 1, "soar": 1, "soars": 1,
+	"soared": 1, "higher": 1, "increase": 1, "increased": 1, "grew": 1, "grows": 1, "advanced": 1,
+	"down": -1, "fall": -1, "falls": -1, "fell": -1, "decline": -1, "declines": -1, "declined": -1,
+	"drop": -1, "drops": -1, "dropped": -1, "slump": -1, "slumps": -1, "slumped": -1, "plunge": -1,
+	"plunges": -1, "plunged": -1, "tumble": -1, "tumbles": -1, "tumbled": -1, "slip": -1, "slips": -1,
+	"slipped": -1, "shed": -1, "sheds": -1, "lower": -1, "decrease": -1, "decreased": -1,
+	"sank": -1, "sinks": -1, "lost": -1, "loses": -1, "slide": -1, "slides": -1, "slid": -1,
+	"crash": -1, "crashes": -1, "crashed": -1, "tank": -1, "tanks": -1, "tanked": -1, "off": -1,
+}
+
+// contextWords is how many words before a number are searched for its
+// label and direction
+const contextWords = 6
+
+// Extract finds the facts in a piece of text, sentence by sentence
+func Extract(text string) []Fact {
+	var found []Fact
+	for i, sentence := range sentences.Split(text) {
+		period := sentencePeriod(sentence)
+		covered := make([][]int, 0)
+
+		for _, m := range amountPattern.FindAllStringSubmatchIndex(sentence, -1) {
+			amount, err := strconv.ParseFloat(strings.ReplaceAll(sentence[m[4]:m[5]], ",", "")+group(sentence, m, 3), 64)
+			if err != nil {
+				continue
+			}
+			unit := UnitINR
+			if strings.Contains(sentence[m[2]:m[3]], "$") || strings.EqualFold(sentence[m[2]:m[3]], "usd") {
+				unit = UnitUSD
+			}
+			fact := Fact{
+				Kind:     KindAmount,
+				Period:   period,
+				Value:    amount,
+				Amount:   amount,
+				Unit:     unit,
+				Text:     strings.TrimSpace(sentence[m[0]:m[1]]),
+				Sentence: i,
+				Context:  sentence,
+			}
+			if scale, ok := scales[strings.ToLower(group(sentence, m, 4))]; ok {
+				fact.Scale = scale.name
+				fact.Value = amount * scale.value
+			}
+			before := lastWords(sentence[:m[0]], contextWords)
+			fact.Label = findMetric(before)
+			if fact.Label == "" {
+				// "bags ₹500 crore order"
+				fact.Label = leadingMetric(sentence[m[1]:])
+			}
+			if targetLabels[fact.Label] {
+				fact.Kind = KindPriceTarget
+				fact.Label = "target price"
+			}
+			fact.Value = round(fact.Value)
+			found = append(found, fact)
+			covered = append(covered, m[:2])
+		}
+
+		for _, m := range percentPattern.FindAllStringSubmatchIndex(sentence, -1) {
+			if overlaps(covered, m[0], m[1]) || partOfNumber(sentence, m[0]) {
+				continue
+			}
+			amount, err := strconv.ParseFloat(sentence[m[2]:m[3]], 64)
+			if err != nil {
+				continue
+			}
+			before := lastWords(sentence[:m[0]], contextWords)
+			value := amount
+			if value > 0 && !strings.HasPrefix(sentence[m[2]:m[3]], "+") {
+				value *= direction(before)
+			}
+			found = append(found, Fact{
+				Kind:     KindPercent,
+				Label:    findMetric(before),
+				Period:   period,
+				Value:    value,
+				Amount:   amount,
+				Unit:     UnitPercent,
+				Text:     strings.TrimSpace(sentence[m[0]:m[1]]),
+				Sentence: i,
+				Context:  sentence,
+			})
+		}
+	}
+	return found
+}
+
+func group(s string, m []int, n int) string {
+	if m[2*n] < 0 {
+		return ""
+	}
+	return s[m[2*n]:m[2*n+1]]
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func overlaps(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && end > span[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// partOfNumber reports whether a match starts inside a longer number, as in
+// the "5%" of "1.5%" when the pattern resumed after a decimal point
+func partOfNumber(s string, start int) bool {
+	return start > 0 && (s[start-1] == '.' || s[start-1] == ',' || unicode.IsDigit(rune(s[start-1])))
+}
+
+// sentencePeriod finds the first reporting period a sentence mentions,
+// written as "Q2 FY26", "H1" or "FY25"
+func sentencePeriod(sentence string) string {
+	m := periodPattern.FindStringSubmatch(sentence)
+	if m == nil {
+		return ""
+	}
+	if m[1] == "" {
+		return "FY" + m[3][len(m[3])-2:]
+	}
+	period := strings.ToUpper(m[1])
+	if m[2] != "" {
+		period += " FY" + m[2][len(m[2])-2:]
+	}
+	return period
+}
+
+// lastWords returns up to n lower-cased words at the end of text
+func lastWords(text string, n int) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > n {
+		words = words[len(words)-n:]
+	}
+	return words
+}
+
+// findMetric returns the metric mentioned nearest the end of words
+func findMetric(words []string) string {
+	for end := len(words); end > 0; end-- {
+		for _, metric := range metrics {
+			parts := strings.Fields(metric)
+			if len(parts) > end {
+				continue
+			}
+			match := true
+			for j, part := range parts {
+				if words[end-len(parts)+j] != part {
+					match = false
+					break
+				}
+			}
+			if match {
+				return metric
+			}
+		}
+	}
+	return ""
+}
+
+// leadingMetric returns the metric text starts with, if any
+func leadingMetric(text string) string {
+	if len(text) > 40 {
+		text = text[:40]
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, metric := range metrics {
+		parts := strings.Fields(metric)
+		if len(parts) <= len(words) && strings.Join(words[:len(parts)], " ") == metric {
+			return metric
+		}
+	}
+	return ""
+}
+
+// direction is the sign given by the move word nearest the end of words, or
+// 1 when none is stated
+func direction(words []string) float64 {
+	for i := len(words) - 1; i >= 0; i-- {
+		if sign, ok := directions[words[i]]; ok {
+			return sign
+		}
+	}
+	return 1
+}
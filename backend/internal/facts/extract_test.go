@@ -0,0 +1,49 @@
+package facts
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		text string
+		want Fact
+	}{
+		{"The company reported revenue of ₹2,340 crore.", Fact{Kind: KindAmount, Label: "revenue", Value: 23400000000, Amount: 2340, Scale: "crore", Unit: UnitINR}},
+		{"Shares were up 4.5% in early trade.", Fact{Kind: KindPercent, Value: 4.5, Amount: 4.5, Unit: UnitPercent}},
+		{"The stock fell 3 per cent on Monday.", Fact{Kind: KindPercent, Value: -3, Amount: 3, Unit: UnitPercent}},
+		{"Buy with a target price of Rs 1,850, says Motilal.", Fact{Kind: KindPriceTarget, Label: "target price", Value: 1850, Amount: 1850, Unit: UnitINR}},
+		{"Q2 net profit rose 12% YoY.", Fact{Kind: KindPercent, Label: "net profit", Period: "Q2", Value: 12, Amount: 12, Unit: UnitPercent}},
+		{"Q1 FY26 net profit at Rs. 512.5 crore.", Fact{Kind: KindAmount, Label: "net profit", Period: "Q1 FY26", Value: 5125000000, Amount: 512.5, Scale: "crore", Unit: UnitINR}},
+		{"RBI infused Rs 2.5 lakh crore of liquidity.", Fact{Kind: KindAmount, Value: 2.5e12, Amount: 2.5, Scale: "lakh crore", Unit: UnitINR}},
+		{"Mizuho will buy Avendus at a $700 million valuation.", Fact{Kind: KindAmount, Label: "valuation", Value: 7e8, Amount: 700, Scale: "million", Unit: UnitUSD}},
+		{"The FY25 dividend is ₹15 lakh per director.", Fact{Kind: KindAmount, Label: "dividend", Period: "FY25", Value: 1.5e6, Amount: 15, Scale: "lakh", Unit: UnitINR}},
+	}
+
+	for _, tt := range tests {
+		got := Extract(tt.text)
+		if len(got) != 1 {
+			t.Errorf("Extract(%q) = %+v, want one fact", tt.text, got)
+			continue
+		}
+		got[0].Text, got[0].Sentence, got[0].Context = "", 0, ""
+		if got[0] != tt.want {
+			t.Errorf("Extract(%q) = %+v, want %+v", tt.text, got[0], tt.want)
+		}
+	}
+}
+
+func TestExtractSentences(t *testing.T) {
+	text := "Infosys shares rose 2%. Wipro fell 1.5% after Rs. 300 crore deal talks. Mr. Parekh declined to comment."
+	got := Extract(text)
+	if len(got) != 3 {
+		t.Fatalf("Extract = %+v, want 3 facts", got)
+	}
+	if got[0].Sentence != 0 || got[1].Sentence != 1 || got[2].Sentence != 1 {
+		t.Errorf("sentence indices = %d, %d, %d, want 0, 1, 1", got[0].Sentence, got[1].Sentence, got[2].Sentence)
+	}
+	if got[1].Label != "deal" || got[1].Value != 3e9 {
+		t.Errorf("deal amount = %+v", got[1])
+	}
+	if got[2].Value != -1.5 {
+		t.Errorf("percentage = %+v, want -1.5", got[2])
+	}
+}
@@ -0,0 +1,92 @@
+package fetch
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// bodySelectors mark the article body on common news page templates, most
+// specific first
+var bodySelectors = []string{
+	`[itemprop="articleBody"]`,
+	".artText",         // Economic Times
+	".storyParagraph",  // Livemint
+	".content_wrapper", // Moneycontrol
+	".storycontent",
+	".story-details",
+	".article-body",
+	".article-content",
+	"article",
+}
+
+// boilerplate is removed before looking for the body
+const boilerplate = `script, style, noscript, nav, header, footer, aside, form, iframe, figure, ` +
+	`[class*="related"], [class*="Related"], [class*="comment"], [class*="share"], ` +
+	`[class*="advert"], [class*="newsletter"], [class*="breadcrumb"], [role="navigation"]`
+
+// minParagraph is the length below which a paragraph is likely a caption,
+// byline or link rather than article text
+const minParagraph = 40
+
+// MainText returns the readable article text of a page. Plain text is
+// returned as is. For HTML, the paragraphs of the article body are used: a
+// known body container if the page has one, otherwise the element holding
+// the most paragraph text.
+func MainText(page *Page) (string, error) {
+	if page.ContentType == "text/plain" {
+		return string(page.Body), nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page.Body))
+	if err != nil {
+		return "", err
+	}
+	doc.Find(boilerplate).Remove()
+
+	for _, selector := range bodySelectors {
+		if body := doc.Find(selector).First(); body.Length() > 0 {
+			if text := paragraphs(body); len(text) >= minParagraph {
+				return text, nil
+			}
+		}
+	}
+
+	// Score each element by the text of the paragraphs directly inside it,
+	// with a share for its parent, and take the best
+	scores := make(map[*html.Node]int)
+	var best *html.Node
+	doc.Find("p").Each(func(_ int, p *goquery.Selection) {
+		length := len(strings.TrimSpace(p.Text()))
+		if length < minParagraph {
+			return
+		}
+		for depth, parent := 0, p.Get(0).Parent; depth < 2 && parent != nil; depth, parent = depth+1, parent.Parent {
+			scores[parent] += length >> depth
+			if best == nil || scores[parent] > scores[best] {
+				best = parent
+			}
+		}
+	})
+	if best != nil {
+		return paragraphs(doc.FindNodes(best)), nil
+	}
+	return paragraphs(doc.Selection), nil
+}
+
+// paragraphs joins the text of the paragraphs within s, or its whole text
+// when it has none
+func paragraphs(s *goquery.Selection) string {
+	var text []string
+	s.Find("p").Each(func(_ int, p *goquery.Selection) {
+		if t := strings.Join(strings.Fields(p.Text()), " "); t != "" {
+			text = append(text, t)
+		}
+	})
+	if len(text) == 0 {
+		return strings.Join(strings.Fields(s.Text()), " ")
+	}
+	return strings.Join(text, "\n")
+}
@@ -0,0 +1,233 @@
+// Package fetch downloads article pages from the open web without letting
+// callers reach internal services
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Errors callers can check for with errors.Is. They all mean the request was
+// refused rather than that the site failed.
+var (
+	ErrInvalidURL     = errors.New("invalid URL")
+	ErrHostNotAllowed = errors.New("host is not an allowed news site")
+	ErrPrivateAddress = errors.New("address is not publicly routable")
+	ErrTooLarge       = errors.New("page is too large")
+	ErrContentType    = errors.New("unsupported content type")
+	ErrTooManyHops    = errors.New("too many redirects")
+)
+
+// Config limits what a Fetcher may download
+type Config struct {
+	Timeout        time.Duration
+	MaxBodyBytes   int64
+	MaxRedirects   int
+	AllowedDomains []string // hosts and their subdomains that may be fetched
+	// AllowPrivate permits loopback and private addresses, for tests
+	AllowPrivate bool
+	UserAgent    string
+}
+
+// DefaultConfig returns the limits used for summarizing pages
+func DefaultConfig(allowedDomains []string) Config {
+	return Config{
+		Timeout:        15 * time.Second,
+		MaxBodyBytes:   5 << 20,
+		MaxRedirects:   5,
+		AllowedDomains: allowedDomains,
+		UserAgent:      "Mozilla/5.0 (compatible; StockNewsAggregator/1.0)",
+	}
+}
+
+// acceptedTypes are the media types a page may be served as
+var acceptedTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+	"text/plain":            true,
+}
+
+// Page is a downloaded page
+type Page struct {
+	URL         string // after redirects
+	ContentType string
+	Body        []byte
+}
+
+// Fetcher downloads pages from allowed hosts
+type Fetcher struct {
+	config Config
+	client *http.Client
+}
+
+// New creates a Fetcher. Addresses are checked after DNS resolution, at
+// connect time, so a public name that resolves to a private address is
+// refused too.
+func New(config Config) *Fetcher {
+	f := &Fetcher{config: config}
+	dialer := &net.Dialer{
+		Timeout: config.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return f.checkAddress(address)
+		},
+	}
+	transport := &http.Transport{
+		Proxy:                 nil, // a proxy would connect on our behalf, unchecked
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   config.Timeout,
+		ResponseHeaderTimeout: config.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+	f.client = &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return ErrTooManyHops
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f
+}
+
+// Fetch downloads a page, refusing disallowed hosts, private addresses,
+// oversized bodies and content that is not HTML or plain text
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := neturl.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("User-Agent", f.config.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// Surface our own refusals rather than the wrapped transport error
+		for _, refusal := range []error{ErrHostNotAllowed, ErrPrivateAddress, ErrTooManyHops, ErrInvalidURL} {
+			if errors.Is(err, refusal) {
+				return nil, fmt.Errorf("failed to fetch URL: %w", refusal)
+			}
+		}
+		return nil, fmt.Errorf("failed to fetch URL: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch URL: status %d", resp.StatusCode)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !acceptedTypes[mediaType] {
+		return nil, fmt.Errorf("%w: %q", ErrContentType, resp.Header.Get("Content-Type"))
+	}
+
+	if resp.ContentLength > f.config.MaxBodyBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.config.MaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page: %v", err)
+	}
+	if int64(len(body)) > f.config.MaxBodyBytes {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrTooLarge, f.config.MaxBodyBytes)
+	}
+
+	return &Page{URL: resp.Request.URL.String(), ContentType: mediaType, Body: body}, nil
+}
+
+// checkURL refuses non-HTTP schemes, credentials and hosts outside the
+// allowlist
+func (f *Fetcher) checkURL(u *neturl.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials are not allowed", ErrInvalidURL)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+	for _, domain := range f.config.AllowedDomains {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+}
+
+// checkAddress refuses connections to addresses that are not publicly
+// routable
+func (f *Fetcher) checkAddress(address string) error {
+	if f.config.AllowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// nonPublic are ranges IsPrivate and friends do not cover
+var nonPublic = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"240.0.0.0/4",   // reserved
+	"64:ff9b::/96",  // NAT64, which can reach IPv4 private ranges
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// IsPublic reports whether ip is a publicly routable unicast address
+func IsPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, n := range nonPublic {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+// Package llm summarizes articles with a large language model served over an
+// OpenAI-compatible chat completions API, such as a self-hosted model
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Config points a Client at a chat completions endpoint
+type Config struct {
+	BaseURL       string // e.g. http://10.0.0.5:8000/v1
+	APIKey        string // sent as a bearer token when set
+	Model         string
+	Timeout       time.Duration
+	MaxTokens     int // most tokens a completion may have
+	ContextTokens int // the model's context window, prompt and completion together
+	Temperature   float64
+}
+
+// DefaultConfig returns the limits used unless configured otherwise
+func DefaultConfig() Config {
+	return Config{
+		Timeout:       30 * time.Second,
+		MaxTokens:     300,
+		ContextTokens: 4096,
+		Temperature:   0.2,
+	}
+}
+
+// Message is one chat message
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+	Temperature float64   `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Client calls a chat completions endpoint
+type Client struct {
+	config Config
+	http   *http.Client
+}
+
+// NewClient creates a Client
+func NewClient(config Config) *Client {
+	return &Client{config: config, http: &http.Client{Timeout: config.Timeout}}
+}
+
+// Complete sends messages and returns the model's reply, asking for at most
+// maxTokens tokens
+func (c *Client) Complete(ctx context.Context, messages []Message, maxTokens int) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model:       c.config.Model,
+		Messages:    messages,
+		MaxTokens:   maxTokens,
+		Temperature: c.config.Temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.TrimSuffix(c.config.BaseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("LLM request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read LLM response: %v", err)
+	}
+	var parsed chatResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return "", fmt.Errorf("invalid LLM response (status %d): %v", resp.StatusCode, err)
+	}
+	if parsed.Error != nil {
+		return "", fmt.Errorf("LLM error (status %d): %s", resp.StatusCode, parsed.Error.Message)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("LLM request failed: status %d", resp.StatusCode)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", fmt.Errorf("LLM returned no choices")
+	}
+	content := strings.TrimSpace(parsed.Choices[0].Message.Content)
+	if content == "" {
+		return "", fmt.Errorf("LLM returned an empty reply")
+	}
+	return content, nil
+}
+
+// EstimateTokens approximates how many tokens text takes. Tokenizers differ
+// between models, so this uses the common rule of thumb of four characters
+// per token, which errs high for English news copy.
+func EstimateTokens(text string) int {
+	return (len([]rune(text)) + 3) / 4
+}
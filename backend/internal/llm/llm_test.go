@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/summarize"
+)
+
+const article = "HDFC Bank reported a 12% rise in quarterly net profit to ₹16,000 crore. " +
+	"Net interest income grew 10% on loan growth. " +
+	"Asset quality improved, with gross NPAs falling to 1.2%. " +
+	"The stock closed flat on the NSE."
+
+// stub serves chat completions with reply, recording the last request
+func stub(t *testing.T, status int, reply string, delay time.Duration) (*httptest.Server, *chatRequest) {
+	t.Helper()
+	var got chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("request to %s, want /v1/chat/completions", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(`{"error": {"message": "model overloaded"}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": reply}, "finish_reason": "stop"},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &got
+}
+
+func testConfig(server *httptest.Server) Config {
+	config := DefaultConfig()
+	config.BaseURL = server.URL + "/v1/"
+	config.APIKey = "secret"
+	config.Model = "test-model"
+	config.Timeout = time.Second
+	return config
+}
+
+func TestSummarize(t *testing.T) {
+	server, got := stub(t, http.StatusOK, "Here is a summary:\n- HDFC Bank's **profit** rose 12% to ₹16,000 crore.\n- Asset quality improved.", 0)
+	s, err := NewSummarizer(testConfig(server), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Summarize(article, summarize.Options{MaxSentences: 2, MaxWords: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"HDFC Bank's profit rose 12% to ₹16,000 crore.", "Asset quality improved."}
+	if strings.Join(result.Sentences, "|") != strings.Join(want, "|") {
+		t.Errorf("Sentences = %q, want %q", result.Sentences, want)
+	}
+	if result.Indices != nil {
+		t.Errorf("Indices = %v, want none for an abstractive summary", result.Indices)
+	}
+
+	if got.Model != "test-model" || len(got.Messages) != 2 || got.Messages[0].Role != "system" {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	prompt := got.Messages[1].Content
+	for _, part := range []string{"at most 2 sentences", "no more than 40 words", "gross NPAs falling to 1.2%"} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("prompt does not contain %q:\n%s", part, prompt)
+		}
+	}
+	if got.MaxTokens != 40*tokensPerWord {
+		t.Errorf("max_tokens = %d, want %d", got.MaxTokens, 40*tokensPerWord)
+	}
+}
+
+func TestSummarizeTemplate(t *testing.T) {
+	server, got := stub(t, http.StatusOK, "Profit rose.", 0)
+	s, err := NewSummarizer(testConfig(server), "Be brief.", "TL;DR in {{.Sentences}}: {{.Text}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Summarize(article, summarize.Options{MaxSentences: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Messages[0].Content != "Be brief." || !strings.HasPrefix(got.Messages[1].Content, "TL;DR in 1: HDFC Bank") {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+
+	if _, err := NewSummarizer(testConfig(server), "", "{{.Missing"); err == nil {
+		t.Error("NewSummarizer accepted an invalid template")
+	}
+	other, _ := NewSummarizer(testConfig(server), "Be brief.", "{{.Text}}")
+	if s.Version() == other.Version() {
+		t.Error("Version does not change with the prompt")
+	}
+}
+
+func TestSummarizeErrors(t *testing.T) {
+	failing, _ := stub(t, http.StatusServiceUnavailable, "", 0)
+	slow, _ := stub(t, http.StatusOK, "Too late.", 300*time.Millisecond)
+	empty, _ := stub(t, http.StatusOK, "   ", 0)
+
+	tests := []struct {
+		name   string
+		server *httptest.Server
+		want   string
+	}{
+		{"error status", failing, "model overloaded"},
+		{"timeout", slow, "LLM request failed"},
+		{"empty reply", empty, "empty reply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig(tt.server)
+			config.Timeout = 100 * time.Millisecond
+			s, _ := NewSummarizer(config, "", "")
+			if _, err := s.Summarize(article, summarize.Options{}); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenBudget(t *testing.T) {
+	server, got := stub(t, http.StatusOK, "Profit rose.", 0)
+	config := testConfig(server)
+	config.ContextTokens = 500
+	config.MaxTokens = 100
+	s, _ := NewSummarizer(config, "", "")
+
+	long := strings.Repeat("The lender's deposits grew faster than its loans during the quarter. ", 200)
+	if _, err := s.Summarize(article+" "+long, summarize.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	prompt := EstimateTokens(got.Messages[0].Content) + EstimateTokens(got.Messages[1].Content)
+	if prompt+got.MaxTokens > config.ContextTokens {
+		t.Errorf("prompt of %d tokens and %d for the reply exceed the %d token context", prompt, got.MaxTokens, config.ContextTokens)
+	}
+	if !strings.Contains(got.Messages[1].Content, "HDFC Bank reported") {
+		t.Error("the article's lead was cut")
+	}
+
+	config.ContextTokens = 200
+	s, _ = NewSummarizer(config, "", "")
+	if _, err := s.Summarize(article, summarize.Options{}); err == nil {
+		t.Error("Summarize accepted a context with no room for the article")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	split := []string{"First sentence here.", "Second sentence here.", "Third sentence here."}
+	if got := Truncate(split, 13); got != "First sentence here. Second sentence here." {
+		t.Errorf("Truncate = %q", got)
+	}
+	if got := Truncate([]string{strings.Repeat("x", 100)}, 5); len(got) != 20 {
+		t.Errorf("Truncate of an overlong sentence kept %d characters, want 20", len(got))
+	}
+}
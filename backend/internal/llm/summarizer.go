@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"stock-news-aggregator/internal/sentences"
+	"stock-news-aggregator/internal/summarize"
+)
+
+// DefaultSystemPrompt sets the model up as a careful news editor
+const DefaultSystemPrompt = "You are a financial news editor summarizing Indian market news. " +
+	"Keep company names, figures, percentages and dates exactly as the article states them, " +
+	"and never add facts that are not in the article."
+
+// DefaultPromptTemplate asks for the summary. Templates see the fields of
+// PromptData.
+const DefaultPromptTemplate = `Summarize the article below in {{if eq .Sentences 1}}one sentence{{else}}at most {{.Sentences}} sentences{{end}}` +
+	`{{if .MaxWords}} and no more than {{.MaxWords}} words{{end}}. ` +
+	`Reply with plain sentences only, without a heading, bullets or any preamble.
+
+Article:
+{{.Text}}`
+
+// PromptData is what a prompt template is rendered with
+type PromptData struct {
+	Text      string
+	Sentences int
+	MaxWords  int
+}
+
+const (
+	// tokensPerWord converts a word limit into a completion budget
+	tokensPerWord = 2
+	// minInputTokens is the least article text worth sending; a prompt that
+	// leaves less room than this is a configuration error
+	minInputTokens = 100
+)
+
+// Summarizer is a summarize.Summarizer backed by a chat model. Its
+// summaries are abstractive, so results have no sentence indices.
+type Summarizer struct {
+	client *Client
+	config Config
+	system string
+	prompt *template.Template
+	source string // the template text, for the version
+}
+
+// NewSummarizer creates a Summarizer with the given prompts. Empty prompts
+// use the defaults.
+func NewSummarizer(config Config, systemPrompt, promptTemplate string) (*Summarizer, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("LLM base URL is not set")
+	}
+	if systemPrompt == "" {
+		systemPrompt = DefaultSystemPrompt
+	}
+	if promptTemplate == "" {
+		promptTemplate = DefaultPromptTemplate
+	}
+	prompt, err := template.New("prompt").Option("missingkey=error").Parse(promptTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %v", err)
+	}
+	return &Summarizer{
+		client: NewClient(config),
+		config: config,
+		system: systemPrompt,
+		prompt: prompt,
+		source: promptTemplate,
+	}, nil
+}
+
+func (s *Summarizer) Name() string { return "llm" }
+
+// Version changes with the model and prompts, so cached summaries made with
+// others are not reused
+func (s *Summarizer) Version() string {
+	sum := sha256.Sum256([]byte(s.config.Model + "\x00" + s.system + "\x00" + s.source))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (s *Summarizer) Summarize(text string, opts summarize.Options) (*summarize.Result, error) {
+	split := sentences.Split(text)
+	if len(split) == 0 {
+		return nil, fmt.Errorf("empty text provided")
+	}
+	data := PromptData{Sentences: summarize.SentenceLimit(opts, len(split)), MaxWords: opts.MaxWords}
+
+	maxTokens := s.config.MaxTokens
+	if opts.MaxWords > 0 && opts.MaxWords*tokensPerWord < maxTokens {
+		maxTokens = opts.MaxWords * tokensPerWord
+	}
+
+	// Fit the article into what the context window leaves after the
+	// prompt and the completion
+	overhead, err := s.render(data)
+	if err != nil {
+		return nil, err
+	}
+	budget := s.config.ContextTokens - maxTokens - EstimateTokens(s.system) - EstimateTokens(overhead)
+	if budget < minInputTokens {
+		return nil, fmt.Errorf("LLM context of %d tokens leaves no room for the article", s.config.ContextTokens)
+	}
+	data.Text = Truncate(split, budget)
+
+	prompt, err := s.render(data)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	reply, err := s.client.Complete(ctx, []Message{
+		{Role: "system", Content: s.system},
+		{Role: "user", Content: prompt},
+	}, maxTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &summarize.Result{Sentences: sentences.Split(cleanReply(reply))}
+	if len(result.Sentences) == 0 {
+		return nil, fmt.Errorf("LLM returned no sentences")
+	}
+	return result, nil
+}
+
+func (s *Summarizer) render(data PromptData) (string, error) {
+	var buf bytes.Buffer
+	if err := s.prompt.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// Truncate joins the leading sentences that fit in a token budget. News
+// puts the most important facts first, so the end is what gets cut.
+func Truncate(split []string, budget int) string {
+	var kept []string
+	used := 0
+	for _, sentence := range split {
+		tokens := EstimateTokens(sentence) + 1
+		if used+tokens > budget {
+			break
+		}
+		kept = append(kept, sentence)
+		used += tokens
+	}
+	if len(kept) == 0 {
+		// A single overlong sentence; cut it by characters
+		runes := []rune(split[0])
+		if limit := budget * 4; len(runes) > limit {
+			runes = runes[:limit]
+		}
+		return string(runes)
+	}
+	return strings.Join(kept, " ")
+}
+
+// replyPreamble matches the lead-ins and list markers models add despite
+// being asked not to
+var replyPreamble = regexp.MustCompile(`(?im)^\s*(?:(?:here is|here's) (?:a |the )?summary[^:\n]*:|summary:|[-*•]\s|\d{1,2}[.)]\s)\s*`)
+
+// cleanReply strips preambles, list markers and Markdown emphasis
+func cleanReply(reply string) string {
+	reply = replyPreamble.ReplaceAllString(reply, "")
+	reply = strings.NewReplacer("**", "", "__", "").Replace(reply)
+	return strings.TrimSpace(reply)
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SavedSearch is a stored query that new articles are matched against
+type SavedSearch struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Sources   []string  `json:"sources"`
+	Tickers   []string  `json:"tickers"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Alert records that a newly stored article matched a saved search
+type Alert struct {
+	ID              int64      `json:"id"`
+	SavedSearchID   int64      `json:"savedSearchId"`
+	SavedSearchName string     `json:"savedSearchName"`
+	MatchedAt       time.Time  `json:"matchedAt"`
+	Article         ArticleDTO `json:"article"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// ArticleDTO is the shape of an article in listing responses
+type ArticleDTO struct {
+	ID          int64             `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	URL         string            `json:"url"`
+	ImageURL    string            `json:"urlToImage,omitempty"`
+	Source      Source            `json:"source"`
+	Section     string            `json:"section,omitempty"`
+	PublishedAt time.Time         `json:"publishedAt"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	Tickers     []ArticleTicker   `json:"tickers,omitempty"`
+	Events      []ArticleEvent    `json:"events,omitempty"`
+	Sentiment   *ArticleSentiment `json:"sentiment,omitempty"`
+	Summary     string            `json:"summary,omitempty"`
+}
+
+// ArticleDetailDTO is the full stored article returned by the detail endpoint
+type ArticleDetailDTO struct {
+	ArticleDTO
+	Content       string        `json:"content"`
+	LastScrapedAt time.Time     `json:"lastScrapedAt"`
+	Facts         []ArticleFact `json:"facts"`
+}
+
+// NewArticleDTO converts an article for use in a listing
+func NewArticleDTO(article Article) ArticleDTO {
+	return ArticleDTO{
+		ID:          article.ID,
+		Title:       article.Title,
+		Description: article.Description,
+		URL:         article.URL,
+		ImageURL:    article.ImageURL,
+		Source:      article.Source,
+		Section:     article.Section,
+		PublishedAt: article.PublishedAt,
+		CreatedAt:   article.CreatedAt,
+		Tickers:     article.Tickers,
+		Events:      article.Events,
+		Sentiment:   article.Sentiment,
+		Summary:     article.Summary,
+	}
+}
+
+// NewArticleDTOs converts a list of articles for use in a listing
+func NewArticleDTOs(articles []Article) []ArticleDTO {
+	dtos := make([]ArticleDTO, 0, len(articles))
+	for _, article := range articles {
+		dtos = append(dtos, NewArticleDTO(article))
+	}
+	return dtos
+}
+
+// NewArticleDetailDTO converts an article for the detail endpoint
+func NewArticleDetailDTO(article Article) ArticleDetailDTO {
+	dto := ArticleDetailDTO{
+		ArticleDTO:    NewArticleDTO(article),
+		Content:       article.Content,
+		LastScrapedAt: article.LastScrapedAt,
+		Facts:         article.Facts,
+	}
+	if dto.Facts == nil {
+		dto.Facts = []ArticleFact{}
+	}
+	return dto
+}
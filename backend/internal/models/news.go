@@ -2,16 +2,65 @@ package models
 
 import "time"
 
+// Article is the canonical article model shared by the scrapers, the
+// database layer and the services. Use the DTOs in dto.go for API responses.
 type Article struct {
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Content     string    `json:"content"`
-	URL         string    `json:"url"`
-	ImageURL    string    `json:"urlToImage,omitempty"`
-	Source      Source    `json:"source"`
-	PublishedAt time.Time `json:"publishedAt"`
+	ID            int64
+	Title         string
+	Description   string
+	Content       string
+	URL           string
+	ImageURL      string
+	Source        Source
+	Section       string
+	PublishedAt   time.Time
+	CreatedAt     time.Time
+	LastScrapedAt time.Time
+	Tickers       []ArticleTicker
+	Events        []ArticleEvent
+	Facts         []ArticleFact     // only loaded for a single article
+	Sentiment     *ArticleSentiment // nil until scored
+	Summary       string            // only loaded for listings, "" until summarized
 }
 
 type Source struct {
 	Name string `json:"name"`
-} 
\ No newline at end of file
+}
+
+// ArticleTicker is a listed company an article mentions
+type ArticleTicker struct {
+	Symbol     string  `json:"symbol"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+	Mentions   int     `json:"mentions"`
+}
+
+// ArticleEvent is a corporate or market event type an article covers, such
+// as "earnings" or "dividend", with the keyword score it was matched by
+type ArticleEvent struct {
+	Type  string  `json:"type"`
+	Score float64 `json:"score"`
+}
+
+// ArticleFact is a number stated in an article, such as "₹2,340 crore" or
+// "up 4.5%". Value is normalized to Unit (INR, USD or %), with Amount and
+// Scale giving the number as written, e.g. 2340 and "crore".
+type ArticleFact struct {
+	Kind    string  `json:"kind"` // amount, percent or price_target
+	Label   string  `json:"label,omitempty"`
+	Period  string  `json:"period,omitempty"`
+	Value   float64 `json:"value"`
+	Amount  float64 `json:"amount"`
+	Scale   string  `json:"scale,omitempty"`
+	Unit    string  `json:"unit"`
+	Text    string  `json:"text"`
+	Context string  `json:"context,omitempty"`
+	Symbol  string  `json:"symbol,omitempty"` // the company the number is about
+}
+
+// ArticleSentiment is the tone of an article, from -1 (negative) to 1
+// (positive), with a positive, negative or neutral label
+type ArticleSentiment struct {
+	Score float64 `json:"score"`
+	Label string  `json:"label"`
+}
@@ -0,0 +1,403 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Options control how field values are resolved while parsing
+type Options struct {
+	Now           time.Time      // reference time for today, yesterday and 7d style dates
+	Location      *time.Location // time zone plain dates are taken in
+	ResolveSource func(string) string
+	ResolveTicker func(string) string // maps names such as "RIL" to a symbol
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokTerm
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind   tokenKind
+	pos    int
+	field  string
+	value  string
+	phrase bool
+}
+
+var knownFields = map[string]bool{
+	FieldTitle:  true,
+	FieldSource: true,
+	FieldTicker: true,
+	FieldAfter:  true,
+	FieldBefore: true,
+	FieldOn:     true,
+}
+
+// Parse parses a query such as
+//
+//	source:ET "rate cut" -crypto after:2026-10-01 ticker:RELIANCE
+//
+// Adjacent terms are combined with AND. OR, NOT and parentheses are
+// supported, and a leading "-" negates a term. An empty query returns a nil
+// node. Errors are returned as *ParseError.
+func Parse(input string, opts Options) (Node, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, opts: opts}
+	if p.peek().kind == tokEOF {
+		return nil, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &ParseError{Position: tok.pos, Message: "unexpected " + describe(tok)}
+	}
+	return node, nil
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		r := rune(input[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i})
+			i++
+		case r == '-' && i+1 < len(input) && !unicode.IsSpace(rune(input[i+1])) && input[i+1] != ')':
+			tokens = append(tokens, token{kind: tokNot, pos: i})
+			i++
+		case r == '"':
+			value, next, err := lexPhrase(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokTerm, pos: i, value: value, phrase: true})
+			i = next
+		default:
+			start := i
+			for i < len(input) && !isDelimiter(input[i]) {
+				i++
+			}
+			word := input[start:i]
+
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, pos: start})
+				continue
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, pos: start})
+				continue
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, pos: start})
+				continue
+			}
+
+			field, value, ok := splitField(word)
+			if !ok {
+				tokens = append(tokens, token{kind: tokTerm, pos: start, value: word})
+				continue
+			}
+			if !knownFields[field] {
+				return nil, &ParseError{Position: start, Message: fmt.Sprintf("unknown field %q", field)}
+			}
+
+			tok := token{kind: tokTerm, pos: start, field: field, value: value}
+			if value == "" && i < len(input) && input[i] == '"' {
+				phrase, next, err := lexPhrase(input, i)
+				if err != nil {
+					return nil, err
+				}
+				tok.value, tok.phrase = phrase, true
+				i = next
+			}
+			if tok.value == "" {
+				return nil, &ParseError{Position: start, Message: fmt.Sprintf("missing value for %s:", field)}
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(input)}), nil
+}
+
+// lexPhrase reads a quoted phrase starting at the opening quote
+func lexPhrase(input string, start int) (string, int, error) {
+	end := strings.IndexByte(input[start+1:], '"')
+	if end < 0 {
+		return "", 0, &ParseError{Position: start, Message: "unterminated phrase"}
+	}
+	phrase := strings.TrimSpace(input[start+1 : start+1+end])
+	if phrase == "" {
+		return "", 0, &ParseError{Position: start, Message: "empty phrase"}
+	}
+	return phrase, start + end + 2, nil
+}
+
+func isDelimiter(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '(' || b == ')' || b == '"'
+}
+
+// splitField splits "field:value" when the prefix is a plain word
+func splitField(word string) (string, string, bool) {
+	idx := strings.IndexByte(word, ':')
+	if idx <= 0 {
+		return "", "", false
+	}
+	for _, r := range word[:idx] {
+		if !unicode.IsLetter(r) {
+			return "", "", false
+		}
+	}
+	return strings.ToLower(word[:idx]), word[idx+1:], true
+}
+
+// Limits on query size. Nested groups and long chains of terms both deepen
+// the SQL expression a query compiles to, and SQLite refuses expressions
+// past a fixed depth.
+const (
+	maxDepth = 20  // nested parentheses and NOTs
+	maxTerms = 100 // search terms in the whole query
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+	opts   Options
+	depth  int // parentheses and NOTs around the current token
+	terms  int
+}
+
+// enter descends into a parenthesis or NOT at tok, refusing to go past
+// maxDepth. The caller must call p.leave once done.
+func (p *parser) enter(tok token) error {
+	if p.depth >= maxDepth {
+		return &ParseError{Position: tok.pos, Message: fmt.Sprintf("query is nested too deeply (at most %d levels)", maxDepth)}
+	}
+	p.depth++
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []Node{left}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &Or{Children: children}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []Node{first}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokTerm, tokNot, tokLParen:
+		default:
+			if len(children) == 1 {
+				return first, nil
+			}
+			return &And{Children: children}, nil
+		}
+
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokNot {
+		if err := p.enter(p.next()); err != nil {
+			return nil, err
+		}
+		child, err := p.parseUnary()
+		p.leave()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		if err := p.enter(tok); err != nil {
+			return nil, err
+		}
+		node, err := p.parseOr()
+		p.leave()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, &ParseError{Position: tok.pos, Message: "missing closing parenthesis"}
+		}
+		p.next()
+		return node, nil
+	case tokTerm:
+		if p.terms++; p.terms > maxTerms {
+			return nil, &ParseError{Position: tok.pos, Message: fmt.Sprintf("query has too many terms (at most %d)", maxTerms)}
+		}
+		return p.termNode(tok)
+	default:
+		return nil, &ParseError{Position: tok.pos, Message: "expected a search term but found " + describe(tok)}
+	}
+}
+
+func (p *parser) termNode(tok token) (Node, error) {
+	switch tok.field {
+	case FieldAfter, FieldBefore, FieldOn:
+		day, err := p.parseDate(tok.value)
+		if err != nil {
+			return nil, &ParseError{Position: tok.pos, Message: err.Error()}
+		}
+		switch tok.field {
+		case FieldAfter:
+			return &DateRange{From: day}, nil
+		case FieldBefore:
+			return &DateRange{To: day}, nil
+		default:
+			day = startOfDay(day)
+			return &DateRange{From: day, To: day.AddDate(0, 0, 1)}, nil
+		}
+	case FieldSource:
+		value := tok.value
+		if p.opts.ResolveSource != nil {
+			value = p.opts.ResolveSource(value)
+		}
+		return &Term{Field: FieldSource, Value: value, Phrase: tok.phrase}, nil
+	case FieldTicker:
+		value := strings.ToUpper(tok.value)
+		if p.opts.ResolveTicker != nil {
+			value = p.opts.ResolveTicker(tok.value)
+		}
+		return &Term{Field: FieldTicker, Value: value}, nil
+	default:
+		return &Term{Field: tok.field, Value: tok.value, Phrase: tok.phrase}, nil
+	}
+}
+
+// parseDate accepts YYYY-MM-DD, today, yesterday, or a relative duration
+// such as 24h, 7d or 2w meaning that long before now
+func (p *parser) parseDate(value string) (time.Time, error) {
+	now := p.opts.Now.In(p.opts.Location)
+	switch strings.ToLower(value) {
+	case "today":
+		return startOfDay(now), nil
+	case "yesterday":
+		return startOfDay(now).AddDate(0, 0, -1), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, p.opts.Location); err == nil {
+		return t, nil
+	}
+
+	if len(value) >= 2 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n >= 0 {
+			switch value[len(value)-1] {
+			case 'h':
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD, today, yesterday or a duration like 7d", value)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokEOF:
+		return "end of query"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "NOT"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	default:
+		return fmt.Sprintf("%q", tok.value)
+	}
+}
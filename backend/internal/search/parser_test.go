@@ -0,0 +1,121 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testOptions = Options{
+	Now:      time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC),
+	Location: time.UTC,
+	ResolveSource: func(name string) string {
+		if strings.EqualFold(name, "ET") {
+			return "Economic Times"
+		}
+		return name
+	},
+	ResolveTicker: func(name string) string {
+		if strings.EqualFold(name, "RIL") {
+			return "RELIANCE"
+		}
+		return strings.ToUpper(name)
+	},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "<nil>"},
+		{"reliance", "reliance"},
+		{"rate cut", "(rate AND cut)"},
+		{`"rate cut"`, `"rate cut"`},
+		{"-crypto", "NOT crypto"},
+		{"NOT crypto", "NOT crypto"},
+		{"a OR b c", "(a OR (b AND c))"},
+		{"a AND (b OR c)", "(a AND (b OR c))"},
+		{"source:ET", "source:Economic Times"},
+		{`source:"Business Standard"`, `source:"Business Standard"`},
+		{"ticker:reliance", "ticker:RELIANCE"},
+		{"ticker:RIL", "ticker:RELIANCE"},
+		{`ticker:"Tata Motors"`, `ticker:TATA MOTORS`},
+		{`title:"block deal"`, `title:"block deal"`},
+		{"after:2026-10-01", "published>=2026-10-01T00:00:00Z"},
+		{"before:yesterday", "published<2026-10-17T00:00:00Z"},
+		{"on:today", "published>=2026-10-18T00:00:00Z,<2026-10-19T00:00:00Z"},
+		{"after:24h", "published>=2026-10-17T15:00:00Z"},
+		{"Q2-FY25", "Q2-FY25"},
+		{
+			`source:ET "rate cut" -crypto after:2026-10-01 ticker:RELIANCE`,
+			`(source:Economic Times AND "rate cut" AND NOT crypto AND published>=2026-10-01T00:00:00Z AND ticker:RELIANCE)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query, testOptions)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.query, err)
+			}
+			got := "<nil>"
+			if node != nil {
+				got = node.String()
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		position int
+		message  string
+	}{
+		{`"rate cut`, 0, "unterminated phrase"},
+		{"a (b OR c", 2, "missing closing parenthesis"},
+		{"a OR", 4, "expected a search term"},
+		{"a )", 2, "unexpected"},
+		{"sector:banks", 0, "unknown field"},
+		{"after:soon", 0, "invalid date"},
+		{"x source:", 2, "missing value"},
+		{strings.Repeat("(", maxDepth+1) + "a", maxDepth, "nested too deeply"},
+		{strings.Repeat("NOT ", maxDepth+1) + "a", 4 * maxDepth, "nested too deeply"},
+		{"a " + strings.Repeat("-(", maxDepth) + "b", 2 + 2*(maxDepth/2), "nested too deeply"},
+		{strings.Repeat("a ", maxTerms) + "b", 2 * maxTerms, "too many terms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query, testOptions)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want *ParseError", tt.query, err)
+			}
+			if parseErr.Position != tt.position {
+				t.Errorf("Parse(%q) error position = %d, want %d", tt.query, parseErr.Position, tt.position)
+			}
+			if !strings.Contains(parseErr.Message, tt.message) {
+				t.Errorf("Parse(%q) error message = %q, want it to contain %q", tt.query, parseErr.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestParseAtLimits(t *testing.T) {
+	queries := []string{
+		strings.Repeat("(", maxDepth) + "a" + strings.Repeat(")", maxDepth),
+		strings.Repeat("NOT ", maxDepth) + "a",
+		strings.TrimSpace(strings.Repeat("a ", maxTerms)),
+	}
+	for _, q := range queries {
+		if _, err := Parse(q, testOptions); err != nil {
+			t.Errorf("Parse(%.40q...) error = %v, want none at the limit", q, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Node is a parsed query expression
+type Node interface {
+	String() string
+}
+
+// And matches when every child matches
+type And struct {
+	Children []Node
+}
+
+// Or matches when any child matches
+type Or struct {
+	Children []Node
+}
+
+// Not matches when its child does not
+type Not struct {
+	Child Node
+}
+
+// Fields accepted as qualifiers, e.g. source:ET or after:2026-10-01
+const (
+	FieldText   = ""
+	FieldTitle  = "title"
+	FieldSource = "source"
+	FieldTicker = "ticker"
+	FieldAfter  = "after"
+	FieldBefore = "before"
+	FieldOn     = "on"
+)
+
+// Term matches a single word, phrase or field qualifier
+type Term struct {
+	Field  string
+	Value  string
+	Phrase bool
+}
+
+// DateRange matches articles published in [From, To). Either bound may be zero.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func (n *And) String() string { return "(" + joinNodes(n.Children, " AND ") + ")" }
+func (n *Or) String() string  { return "(" + joinNodes(n.Children, " OR ") + ")" }
+func (n *Not) String() string { return "NOT " + n.Child.String() }
+
+func (n *Term) String() string {
+	value := n.Value
+	if n.Phrase {
+		value = `"` + value + `"`
+	}
+	if n.Field == FieldText {
+		return value
+	}
+	return n.Field + ":" + value
+}
+
+func (n *DateRange) String() string {
+	var parts []string
+	if !n.From.IsZero() {
+		parts = append(parts, ">="+n.From.Format(time.RFC3339))
+	}
+	if !n.To.IsZero() {
+		parts = append(parts, "<"+n.To.Format(time.RFC3339))
+	}
+	return "published" + strings.Join(parts, ",")
+}
+
+func joinNodes(nodes []Node, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, sep)
+}
+
+// ParseError describes why a query could not be parsed
+type ParseError struct {
+	Position int    `json:"position"` // byte offset into the query
+	Message  string `json:"message"`
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+// Keywords returns the free-text words and phrases that are not negated,
+// which is what relevance ranking scores against
+func Keywords(node Node) []string {
+	var keywords []string
+	var walk func(Node)
+	walk = func(node Node) {
+		switch n := node.(type) {
+		case *And:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *Or:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *Term:
+			if n.Field == FieldText || n.Field == FieldTitle {
+				keywords = append(keywords, n.Value)
+			}
+		}
+	}
+	walk(node)
+	return keywords
+}
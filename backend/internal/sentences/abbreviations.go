@@ -0,0 +1,39 @@
+package sentences
+
+// Abbreviations never end a sentence: honorifics, currency and the like,
+// which are always followed by a name or a number. Keys are lower-case
+// without the period.
+var Abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+	"sh": true, "smt": true, "shri": true, "km": true, "st": true, "hon'ble": true,
+	"adv": true, "capt": true, "col": true, "gen": true, "lt": true, "maj": true, "brig": true,
+	"rs": true, "re": true, "inr": true, "vs": true, "viz": true, "approx": true, "est": true,
+	"govt": true, "dept": true, "min": true, "max": true, "avg": true, "appx": true,
+	"e.g": true, "i.e": true, "cf": true, "ca": true, "a.k.a": true,
+}
+
+// NumberAbbreviations only continue a sentence when a number follows, as
+// in "No. 5" or "Jan. 12"
+var NumberAbbreviations = map[string]bool{
+	"no": true, "nos": true, "art": true, "sec": true, "fig": true, "vol": true, "pp": true,
+	"jan": true, "feb": true, "mar": true, "apr": true, "jun": true, "jul": true, "aug": true,
+	"sep": true, "sept": true, "oct": true, "nov": true, "dec": true,
+}
+
+// Suffixes often end a sentence but also appear mid-sentence, as in
+// "Reliance Industries Ltd. shares" or "the U.S. market". After one of these
+// a sentence only ends when the next word is a common sentence opener.
+var Suffixes = map[string]bool{
+	"ltd": true, "pvt": true, "co": true, "corp": true, "inc": true, "plc": true,
+	"llp": true, "bros": true, "etc": true, "cr": true, "bn": true, "mn": true,
+}
+
+// openers are words that usually start a sentence
+var openers = map[string]bool{
+	"the": true, "a": true, "an": true, "it": true, "its": true, "this": true, "that": true,
+	"these": true, "those": true, "he": true, "she": true, "they": true, "we": true, "i": true,
+	"but": true, "and": true, "however": true, "meanwhile": true, "shares": true, "in": true,
+	"on": true, "at": true, "for": true, "according": true, "analysts": true, "also": true,
+	"while": true, "after": true, "as": true, "last": true, "earlier": true, "there": true,
+	"his": true, "her": true, "their": true, "our": true, "with": true, "from": true,
+}
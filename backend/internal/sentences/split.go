@@ -0,0 +1,159 @@
+// Package sentences splits news text into sentences
+package sentences
+
+import (
+	"strings"
+	"unicode"
+)
+
+// closers may follow the punctuation that ends a sentence
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', '”', '’', ')', ']', '»':
+		return true
+	}
+	return false
+}
+
+// isOpener reports whether r may precede the first word of a sentence
+func isOpener(r rune) bool {
+	switch r {
+	case '"', '\'', '“', '‘', '(', '[', '«':
+		return true
+	}
+	return false
+}
+
+func isTerminal(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+// Split breaks text into trimmed sentences. A sentence ends at ".", "!", "?"
+// or an ellipsis, together with any closing quotes or brackets, when the
+// next sentence starts with a capital letter, a digit, a currency sign or an
+// opening quote. Periods in decimals, abbreviations such as "Rs.", initials
+// such as "N. Chandrasekaran" and initialisms such as "U.S." do not end a
+// sentence. Line breaks end a sentence on the same terms.
+func Split(text string) []string {
+	runes := []rune(text)
+	var result []string
+	start := 0
+	emit := func(end int) {
+		if s := strings.TrimSpace(string(runes[start:end])); s != "" {
+			result = append(result, s)
+		}
+		start = end
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\n' {
+			if startsSentence(runes, i+1) {
+				emit(i + 1)
+			}
+			continue
+		}
+		if !isTerminal(r) {
+			continue
+		}
+
+		// Take in a run of terminal punctuation and closing quotes, as in
+		// '?!', '...' and '."'
+		end := i + 1
+		for end < len(runes) && (isTerminal(runes[end]) || isCloser(runes[end])) {
+			end++
+		}
+		if end < len(runes) && !unicode.IsSpace(runes[end]) {
+			// "5.5%", "U.S", "Yahoo!Finance"
+			i = end - 1
+			continue
+		}
+		if end < len(runes) && (!startsSentence(runes, end) || !periodEnds(runes, i, end)) {
+			i = end - 1
+			continue
+		}
+		emit(end)
+		i = end - 1
+	}
+	emit(len(runes))
+	return result
+}
+
+// startsSentence reports whether the text after position i, past any
+// whitespace and opening quotes, looks like the start of a sentence
+func startsSentence(runes []rune, i int) bool {
+	for i < len(runes) && (unicode.IsSpace(runes[i]) || isOpener(runes[i])) {
+		i++
+	}
+	if i == len(runes) {
+		return true
+	}
+	r := runes[i]
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || r == '₹' || r == '$'
+}
+
+// periodEnds decides whether a single period at runes[i] ends a sentence
+// given the word before it. Other terminal punctuation always does.
+func periodEnds(runes []rune, i, end int) bool {
+	if runes[i] != '.' || end-i > 1 && runes[i+1] == '.' {
+		return true
+	}
+
+	wordStart := i
+	for wordStart > 0 && !unicode.IsSpace(runes[wordStart-1]) && !isOpener(runes[wordStart-1]) {
+		wordStart--
+	}
+	word := string(runes[wordStart:i])
+	lower := strings.ToLower(word)
+	next := nextWord(runes, end)
+
+	switch {
+	case word == "":
+		return true
+	case Abbreviations[lower]:
+		return false
+	case NumberAbbreviations[lower]:
+		return next == "" || !unicode.IsDigit([]rune(next)[0])
+	case isInitial(word):
+		return false
+	case Suffixes[lower] || isInitialism(word):
+		return next == "" || openers[strings.ToLower(next)]
+	}
+	return true
+}
+
+// nextWord returns the word starting after position i, past whitespace and
+// opening quotes
+func nextWord(runes []rune, i int) string {
+	for i < len(runes) && (unicode.IsSpace(runes[i]) || isOpener(runes[i])) {
+		i++
+	}
+	j := i
+	for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+		j++
+	}
+	return string(runes[i:j])
+}
+
+// isInitial reports whether word is a single capital letter, as in
+// "Mukesh D. Ambani"
+func isInitial(word string) bool {
+	runes := []rune(word)
+	return len(runes) == 1 && unicode.IsUpper(runes[0])
+}
+
+// isInitialism reports whether word is letters separated by periods, as in
+// "U.S" before its final period
+func isInitialism(word string) bool {
+	parts := strings.Split(word, ".")
+	if len(parts) < 2 {
+		return false
+	}
+	for _, part := range parts {
+		runes := []rune(part)
+		if len(runes) != 1 || !unicode.IsLetter(runes[0]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package sentences
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"simple", "Sensex rose. Nifty fell! Why? Nobody knows.",
+			[]string{"Sensex rose.", "Nifty fell!", "Why?", "Nobody knows."}},
+		{"decimals", "The repo rate is now 5.5%. Inflation was 3.16% in April.",
+			[]string{"The repo rate is now 5.5%.", "Inflation was 3.16% in April."}},
+		{"currency", "RBI released Rs. 2.5 lakh crore into the system. Banks gained.",
+			[]string{"RBI released Rs. 2.5 lakh crore into the system.", "Banks gained."}},
+		{"rupee sign", "Shares hit ₹1,297.40. ₹500 crore was raised.",
+			[]string{"Shares hit ₹1,297.40.", "₹500 crore was raised."}},
+		{"company suffix mid-sentence", "Reliance Industries Ltd. shares rose 2%.",
+			[]string{"Reliance Industries Ltd. shares rose 2%."}},
+		{"company suffix ending a sentence", "The deal was signed with Tata Sons Pvt. Ltd. The stock rose.",
+			[]string{"The deal was signed with Tata Sons Pvt. Ltd.", "The stock rose."}},
+		{"versus", "Q2FY25 vs. Q1 shows a 12% rise in profit.",
+			[]string{"Q2FY25 vs. Q1 shows a 12% rise in profit."}},
+		{"initialism mid-sentence", "The U.S. Federal Reserve held rates.",
+			[]string{"The U.S. Federal Reserve held rates."}},
+		{"initialism ending a sentence", "Exports fell to the U.S. The rupee weakened.",
+			[]string{"Exports fell to the U.S.", "The rupee weakened."}},
+		{"initials", "Tata Sons chairman N. Chandrasekaran spoke. Mukesh D. Ambani did not.",
+			[]string{"Tata Sons chairman N. Chandrasekaran spoke.", "Mukesh D. Ambani did not."}},
+		{"honorifics", "Mr. Malhotra said Dr. Rao agreed.",
+			[]string{"Mr. Malhotra said Dr. Rao agreed."}},
+		{"number abbreviation", "It ranks No. 3 in India. No. The company denied it.",
+			[]string{"It ranks No. 3 in India.", "No.", "The company denied it."}},
+		{"quotes", `He said, "Profits rose." The stock jumped. "We are cautious," she added.`,
+			[]string{`He said, "Profits rose."`, "The stock jumped.", `"We are cautious," she added.`}},
+		{"curly quotes", "“Demand is strong.” Analysts agreed.",
+			[]string{"“Demand is strong.”", "Analysts agreed."}},
+		{"ellipsis mid-sentence", "Markets fell... but recovered by noon.",
+			[]string{"Markets fell... but recovered by noon."}},
+		{"ellipsis ending a sentence", "Markets fell… Then they recovered.",
+			[]string{"Markets fell…", "Then they recovered."}},
+		{"lower-case continuation", "Analysts cited e.g. margins and costs. Shares fell.",
+			[]string{"Analysts cited e.g. margins and costs.", "Shares fell."}},
+		{"brackets", "Profit rose (up 12% YoY.) Revenue grew.",
+			[]string{"Profit rose (up 12% YoY.)", "Revenue grew."}},
+		{"line breaks", "Sensex ends flat\nBanks gain as RBI cuts rates",
+			[]string{"Sensex ends flat", "Banks gain as RBI cuts rates"}},
+		{"no punctuation", "Sensex ends flat", []string{"Sensex ends flat"}},
+		{"empty", "   ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%q)\n got %q\nwant %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func FuzzSplit(f *testing.F) {
+	for _, seed := range []string{
+		"Sensex rose. Nifty fell!",
+		"RBI released Rs. 2.5 lakh crore. Banks gained.",
+		`He said, "Profits rose." The U.S. Fed... held.`,
+		"N. Chandrasekaran said No. 3 vs. Q1… “Yes.”",
+		"...", ". . .", "\n\n", "a.b.c. D",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		if !utf8.ValidString(text) {
+			t.Skip()
+		}
+		got := Split(text)
+		for _, s := range got {
+			if s == "" || s != strings.TrimSpace(s) {
+				t.Fatalf("Split(%q) returned untrimmed or empty sentence %q", text, s)
+			}
+		}
+		// Splitting only drops whitespace between sentences
+		if joined := stripSpace(strings.Join(got, "")); joined != stripSpace(text) {
+			t.Fatalf("Split(%q) = %q lost or changed text", text, got)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/search"
+)
+
+// ErrEmptySavedSearch is returned when a saved search has nothing to match on
+var ErrEmptySavedSearch = errors.New("a saved search needs a query, sources or tickers")
+
+// CreateSavedSearch validates and stores a saved search. Sources accept the
+// same short names as the news API and tickers accept company names and
+// aliases such as "RIL". Query syntax errors are returned as
+// *search.ParseError.
+func CreateSavedSearch(s models.SavedSearch) (*models.SavedSearch, error) {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Query = strings.TrimSpace(s.Query)
+
+	sources := []string{}
+	for _, source := range s.Sources {
+		if strings.TrimSpace(source) != "" {
+			sources = append(sources, CanonicalSource(source))
+		}
+	}
+	s.Sources = sources
+
+	tickers := []string{}
+	for _, ticker := range s.Tickers {
+		if strings.TrimSpace(ticker) != "" {
+			tickers = append(tickers, ResolveTicker(ticker))
+		}
+	}
+	s.Tickers = tickers
+
+	if s.Query == "" && len(s.Sources) == 0 && len(s.Tickers) == 0 {
+		return nil, ErrEmptySavedSearch
+	}
+	if _, err := savedSearchFilter(s); err != nil {
+		return nil, err
+	}
+	if s.Name == "" {
+		s.Name = s.Query
+		if s.Name == "" {
+			s.Name = strings.Join(append(append([]string{}, s.Tickers...), s.Sources...), ", ")
+		}
+	}
+
+	id, err := database.InsertSavedSearch(s)
+	if err != nil {
+		return nil, err
+	}
+	return database.GetSavedSearch(id)
+}
+
+// savedSearchFilter builds the article filter a saved search matches with:
+// its query, any of its tickers, and any of its sources
+func savedSearchFilter(s models.SavedSearch) (database.ArticleFilter, error) {
+	filter := database.ArticleFilter{Sources: s.Sources}
+
+	var conditions []search.Node
+	if s.Query != "" {
+		query, err := ParseQuery(s.Query)
+		if err != nil {
+			return filter, err
+		}
+		if query != nil {
+			conditions = append(conditions, query)
+		}
+	}
+
+	if len(s.Tickers) > 0 {
+		var tickers []search.Node
+		for _, ticker := range s.Tickers {
+			tickers = append(tickers, &search.Term{Field: search.FieldTicker, Value: ticker})
+		}
+		conditions = append(conditions, &search.Or{Children: tickers})
+	}
+
+	if len(conditions) > 0 {
+		filter.Query = &search.And{Children: conditions}
+	}
+	return filter, nil
+}
+
+// MatchSavedSearches checks newly stored articles against every saved search
+// and records an alert for each match
+func MatchSavedSearches(articleIDs []int64) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+
+	searches, err := database.GetSavedSearches()
+	if err != nil {
+		return err
+	}
+
+	var totalAlerts int
+	for _, s := range searches {
+		filter, err := savedSearchFilter(s)
+		if err != nil {
+			// The query parsed when it was saved, but may not after the
+			// query language changes; skip it rather than fail the batch
+			log.Printf("Skipping saved search %d (%s): %v", s.ID, s.Name, err)
+			continue
+		}
+
+		matched, err := database.MatchArticles(articleIDs, filter)
+		if err != nil {
+			return err
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		if err := database.InsertAlerts(s.ID, matched); err != nil {
+			return err
+		}
+		totalAlerts += len(matched)
+		log.Printf("Saved search %q matched %d new articles", s.Name, len(matched))
+	}
+
+	log.Printf("Matched %d new articles against %d saved searches: %d alerts", len(articleIDs), len(searches), totalAlerts)
+	return nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/search"
+)
+
+func TestCreateSavedSearch(t *testing.T) {
+	openTestDB(t)
+
+	saved, err := CreateSavedSearch(models.SavedSearch{Sources: []string{"mint", " "}, Tickers: []string{"tcs"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved.Sources, []string{"Livemint"}) || !reflect.DeepEqual(saved.Tickers, []string{"TCS"}) {
+		t.Errorf("sources %v and tickers %v were not resolved", saved.Sources, saved.Tickers)
+	}
+	if saved.Name != "TCS, Livemint" {
+		t.Errorf("default name = %q, want %q", saved.Name, "TCS, Livemint")
+	}
+
+	if _, err := CreateSavedSearch(models.SavedSearch{Name: "empty", Sources: []string{""}}); !errors.Is(err, ErrEmptySavedSearch) {
+		t.Errorf("empty saved search: error = %v, want ErrEmptySavedSearch", err)
+	}
+	var parseErr *search.ParseError
+	if _, err := CreateSavedSearch(models.SavedSearch{Query: "(rate cut"}); !errors.As(err, &parseErr) {
+		t.Errorf("invalid query: error = %v, want a ParseError", err)
+	}
+}
+
+func TestMatchSavedSearches(t *testing.T) {
+	openTestDB(t)
+
+	insert := func(title, source, description string, tickers ...string) int64 {
+		t.Helper()
+		id, err := database.InsertArticle(models.Article{
+			Title:       title,
+			URL:         "https://example.com/" + title,
+			Source:      models.Source{Name: source},
+			Description: description,
+			PublishedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var links []models.ArticleTicker
+		for _, symbol := range tickers {
+			links = append(links, models.ArticleTicker{Symbol: symbol, Name: symbol, Confidence: 1, Mentions: 1})
+		}
+		if err := database.SaveArticleTickers(id, links, "test"); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	// Stored before the searches were saved, so never alerted on
+	old := insert("Infosys names new CFO", "Livemint", "")
+
+	create := func(s models.SavedSearch) int64 {
+		t.Helper()
+		saved, err := CreateSavedSearch(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return saved.ID
+	}
+	keyword := create(models.SavedSearch{Query: "infosys"})
+	source := create(models.SavedSearch{Sources: []string{"mint"}})
+	ticker := create(models.SavedSearch{Query: "results", Tickers: []string{"TCS"}})
+	combined := create(models.SavedSearch{Query: `(dividend OR buyback) -"record date"`, Sources: []string{"mc", "et"}})
+
+	infosys := insert("IT stocks rally", "Livemint", "Infosys and Wipro lead gains")
+	tcs := insert("TCS quarterly results beat estimates", "Economic Times", "", "TCS")
+	wipro := insert("Wipro results disappoint", "Economic Times", "", "WIPRO")
+	dividend := insert("HDFC Bank declares dividend", "MoneyControl", "")
+	recordDate := insert("ITC dividend record date set", "MoneyControl", "")
+	buyback := insert("Infosys buyback opens", "Business Standard", "")
+
+	newIDs := []int64{infosys, tcs, wipro, dividend, recordDate, buyback}
+	if err := MatchSavedSearches(newIDs); err != nil {
+		t.Fatal(err)
+	}
+	// Matching the same articles again adds no alerts
+	if err := MatchSavedSearches(newIDs); err != nil {
+		t.Fatal(err)
+	}
+
+	alerts, err := database.GetAlerts(0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[int64][]int64)
+	for _, alert := range alerts {
+		got[alert.SavedSearchID] = append(got[alert.SavedSearchID], alert.Article.ID)
+	}
+	for _, ids := range got {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	want := map[int64][]int64{
+		keyword:  {infosys, buyback},
+		source:   {infosys},
+		ticker:   {tcs},
+		combined: {dividend},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("alerts by saved search = %v, want %v", got, want)
+	}
+	for _, alert := range alerts {
+		if alert.Article.ID == old {
+			t.Errorf("article %d stored before the search was alerted on", old)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+)
+
+// ErrNoArticleText means a stored article has neither content nor a
+// description to summarize
+var ErrNoArticleText = errors.New("article has no stored text to summarize")
+
+const (
+	// defaultSummaryWorkers is how many articles are pre-summarized at once
+	// unless SUMMARY_WORKERS says otherwise
+	defaultSummaryWorkers = 4
+	// summaryQueueSize bounds the articles waiting to be pre-summarized.
+	// Articles that do not fit are picked up by the next startup backfill.
+	summaryQueueSize = 1000
+	// summaryBackfillLimit caps how many stored articles are queued at startup,
+	// newest first
+	summaryBackfillLimit = 5000
+)
+
+var (
+	// listSummarizer makes the summaries shown in article listings; nil until
+	// the summary workers are started
+	listSummarizer *TextSummarizer
+	summaryQueue   chan int64
+)
+
+// SummaryWorkers is the number of pre-summarizing workers, from
+// SUMMARY_WORKERS
+func SummaryWorkers() int {
+	if n, err := strconv.Atoi(os.Getenv("SUMMARY_WORKERS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultSummaryWorkers
+}
+
+// articleText is the stored text an article is summarized from: its content,
+// or its description when the content was not scraped or has been stripped
+func articleText(article models.Article) string {
+	if article.Content != "" {
+		return article.Content
+	}
+	return article.Description
+}
+
+// SummarizeArticle summarizes a stored article from its stored text with the
+// given options, without fetching the page. The summary is cached like one
+// made from the article's URL.
+func SummarizeArticle(ts *TextSummarizer, opts SummaryOptions, article models.Article) (*Summary, error) {
+	plan, err := ts.plan(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	key := database.ArticleSummaryKey(article.ID, summaryCacheVersion(), plan.params())
+	key.URL = CanonicalURL(article.URL)
+	return cachedSummary(key, plan, func() (*Summary, error) {
+		text := articleText(article)
+		if text == "" {
+			return nil, ErrNoArticleText
+		}
+		return plan.summarize(text)
+	})
+}
+
+// StartSummaryWorkers pre-summarizes articles with the default algorithm in
+// the background, at most workers at a time, so listings can show them.
+// Stored articles with content but no summary are queued first.
+func StartSummaryWorkers(ts *TextSummarizer, workers int) {
+	listSummarizer = ts
+	summaryQueue = make(chan int64, summaryQueueSize)
+	for i := 0; i < workers; i++ {
+		go summaryWorker(ts)
+	}
+	go backfillArticleSummaries(ts)
+}
+
+func summaryWorker(ts *TextSummarizer) {
+	for id := range summaryQueue {
+		article, err := database.GetArticleByID(id)
+		if err != nil {
+			log.Printf("Error loading article %d to summarize: %v", id, err)
+			continue
+		}
+		// A description alone is already short; listings show it as is
+		if article.Content == "" {
+			continue
+		}
+		if _, err := SummarizeArticle(ts, SummaryOptions{}, *article); err != nil {
+			log.Printf("Error summarizing article %d: %v", id, err)
+		}
+	}
+}
+
+// backfillArticleSummaries queues the stored articles without a current
+// summary, waiting for room in the queue
+func backfillArticleSummaries(ts *TextSummarizer) {
+	plan, _ := ts.plan(SummaryOptions{})
+	ids, err := database.GetArticleIDsToSummarize(summaryCacheVersion(), plan.params(), summaryBackfillLimit)
+	if err != nil {
+		log.Printf("Error finding articles to summarize: %v", err)
+		return
+	}
+	if len(ids) > 0 {
+		log.Printf("Queued %d stored articles for summarizing", len(ids))
+	}
+	for _, id := range ids {
+		summaryQueue <- id
+	}
+}
+
+// QueueArticleSummaries queues newly stored articles for pre-summarizing.
+// It never blocks; articles that do not fit in the queue are left for the
+// next startup.
+func QueueArticleSummaries(ids []int64) {
+	if summaryQueue == nil {
+		return
+	}
+	var dropped int
+	for _, id := range ids {
+		select {
+		case summaryQueue <- id:
+		default:
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		log.Printf("Summary queue full, %d new articles left unsummarized", dropped)
+	}
+}
+
+// attachSummaries fills in the pre-made summaries of the given articles
+func attachSummaries(articles []models.Article) error {
+	if listSummarizer == nil || len(articles) == 0 {
+		return nil
+	}
+	plan, _ := listSummarizer.plan(SummaryOptions{})
+	ids := make([]int64, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+	summaries, err := database.GetArticleSummaries(ids, summaryCacheVersion(), plan.params())
+	if err != nil {
+		return err
+	}
+	for i := range articles {
+		articles[i].Summary = summaries[articles[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+)
+
+const testArticleContent = `Tata Motors reported a sharp rise in quarterly profit on Friday. ` +
+	`Jaguar Land Rover sales drove most of the growth in the quarter. ` +
+	`The company said demand for its electric vehicles stayed strong. ` +
+	`Analysts expect margins to improve as commodity costs ease. ` +
+	`Shares of Tata Motors rose 3% after the results. ` +
+	`The board also approved a plan to split the company into two listed entities. ` +
+	`The demerger is expected to be completed within a year.`
+
+// resetSummaryWorkers forgets the list summarizer and queue once a test ends
+func resetSummaryWorkers(t *testing.T) {
+	t.Cleanup(func() {
+		listSummarizer = nil
+		summaryQueue = nil
+	})
+}
+
+func TestSummaryWorkersBackfillListings(t *testing.T) {
+	openTestDB(t)
+	resetSummaryWorkers(t)
+
+	withContent, err := database.InsertArticle(models.Article{
+		Title:       "Tata Motors profit jumps",
+		URL:         "https://example.com/tata-motors",
+		Source:      models.Source{Name: "Livemint"},
+		Content:     testArticleContent,
+		PublishedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutContent, err := database.InsertArticle(models.Article{
+		Title:       "Sensex ends flat",
+		URL:         "https://example.com/sensex",
+		Source:      models.Source{Name: "Livemint"},
+		Description: "Benchmarks ended little changed.",
+		PublishedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := NewTextSummarizer(2)
+	StartSummaryWorkers(ts, 2)
+
+	plan, _ := ts.plan(SummaryOptions{})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ids, err := database.GetArticleIDsToSummarize(summaryCacheVersion(), plan.params(), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("articles %v still unsummarized", ids)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	articles, _, err := GetNewsFromDB(1, 10, database.ArticleFilter{}, BalanceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaries := make(map[int64]string)
+	for _, article := range articles {
+		summaries[article.ID] = article.Summary
+	}
+	if summaries[withContent] == "" {
+		t.Errorf("article with content has no summary in the listing")
+	}
+	if summaries[withoutContent] != "" {
+		t.Errorf("article without content has summary %q, want none", summaries[withoutContent])
+	}
+}
+
+func TestQueueArticleSummariesDropsWhenFull(t *testing.T) {
+	resetSummaryWorkers(t)
+
+	// Nothing is queued before the workers start
+	QueueArticleSummaries([]int64{1, 2})
+
+	summaryQueue = make(chan int64, 2)
+	QueueArticleSummaries([]int64{1, 2, 3, 4})
+	if len(summaryQueue) != 2 {
+		t.Fatalf("queue holds %d articles, want 2", len(summaryQueue))
+	}
+	for _, want := range []int64{1, 2} {
+		if got := <-summaryQueue; got != want {
+			t.Errorf("dequeued %d, want %d", got, want)
+		}
+	}
+}
+
+func TestSummarizeArticle(t *testing.T) {
+	openTestDB(t)
+	ts := NewTextSummarizer(2)
+
+	article := models.Article{ID: 1, URL: "https://example.com/a", Content: testArticleContent}
+	first, err := SummarizeArticle(ts, SummaryOptions{}, article)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := SummarizeArticle(ts, SummaryOptions{}, article)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached || !second.Cached {
+		t.Errorf("cached = %v then %v, want false then true", first.Cached, second.Cached)
+	}
+	if first.Text != second.Text {
+		t.Errorf("cached summary %q differs from %q", second.Text, first.Text)
+	}
+
+	_, err = SummarizeArticle(ts, SummaryOptions{}, models.Article{ID: 2, URL: "https://example.com/b"})
+	if err != ErrNoArticleText {
+		t.Errorf("article without text: error = %v, want ErrNoArticleText", err)
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"stock-news-aggregator/internal/database"
+)
+
+const (
+	// maxDigestArticles caps how many of a day's articles a digest groups
+	maxDigestArticles = 1000
+	// MaxDigestStories is the most stories a digest can list
+	MaxDigestStories = 30
+	// digestCacheTTL is how long a day's digest is reused before it is
+	// rebuilt with any articles stored since
+	digestCacheTTL = 10 * time.Minute
+)
+
+type cachedDigest struct {
+	digest  *Digest // with up to MaxDigestStories stories
+	builtAt time.Time
+}
+
+var (
+	digestCacheMu sync.Mutex
+	digestCache   = make(map[string]cachedDigest)
+)
+
+// digestIndices are the benchmarks a digest reports closes for
+var digestIndices = []string{"^NSEI", "^BSESN"}
+
+// IndexClose is a benchmark index's close on a trading day
+type IndexClose struct {
+	Symbol     string  `json:"symbol"`
+	Name       string  `json:"name"`
+	Close      float64 `json:"close"`
+	Change     float64 `json:"change"`
+	ChangePerc float64 `json:"changePercentage"`
+}
+
+// Digest is the end-of-day wrap-up: how the benchmarks closed and the day's
+// top stories
+type Digest struct {
+	Date        string       `json:"date"`
+	Markets     []IndexClose `json:"markets"`
+	MarketError string       `json:"marketError,omitempty"`
+	Articles    int          `json:"articles"` // articles published that day
+	Stories     []Story      `json:"stories"`
+}
+
+// ParseDigestDate parses a YYYY-MM-DD date in market time, defaulting to
+// today. Future dates are refused.
+func ParseDigestDate(value string) (time.Time, error) {
+	now := time.Now().In(MarketLocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, MarketLocation)
+	if value == "" {
+		return today, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, MarketLocation)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	if date.After(today) {
+		return time.Time{}, fmt.Errorf("date %s is in the future", value)
+	}
+	return date, nil
+}
+
+// GetDigest builds the digest for a market day: the NIFTY and SENSEX closes
+// and up to limit of the day's stories, each summarized across the sources
+// that reported it. Stories are still returned when closes cannot be
+// fetched, with the reason in MarketError. Digests are cached per day for a
+// few minutes.
+func GetDigest(date time.Time, limit int) (*Digest, error) {
+	day := date.In(MarketLocation).Format("2006-01-02")
+
+	digestCacheMu.Lock()
+	cached, ok := digestCache[day]
+	digestCacheMu.Unlock()
+	if !ok || time.Since(cached.builtAt) >= digestCacheTTL {
+		digest, err := buildDigest(date)
+		if err != nil {
+			return nil, err
+		}
+		cached = cachedDigest{digest: digest, builtAt: time.Now()}
+
+		digestCacheMu.Lock()
+		for key, entry := range digestCache {
+			if time.Since(entry.builtAt) >= digestCacheTTL {
+				delete(digestCache, key)
+			}
+		}
+		digestCache[day] = cached
+		digestCacheMu.Unlock()
+	}
+
+	digest := *cached.digest
+	if len(digest.Stories) > limit {
+		digest.Stories = digest.Stories[:limit]
+	}
+	return &digest, nil
+}
+
+// buildDigest builds the digest for a day with as many stories as a digest
+// can list
+func buildDigest(date time.Time) (*Digest, error) {
+	day := date.In(MarketLocation).Format("2006-01-02")
+	digest := &Digest{Date: day, Markets: []IndexClose{}, Stories: []Story{}}
+
+	markets, err := indexCloses(date)
+	if err != nil {
+		digest.MarketError = err.Error()
+	} else {
+		digest.Markets = markets
+	}
+
+	// Articles without a publish date count on the day they were stored
+	filter := database.ArticleFilter{From: date, To: date.AddDate(0, 0, 1), ByAge: true}
+	articles, total, err := database.GetArticles(1, maxDigestArticles, filter)
+	if err != nil {
+		return nil, err
+	}
+	digest.Articles = total
+
+	stories := GroupStories(articles)
+	if len(stories) > MaxDigestStories {
+		stories = stories[:MaxDigestStories]
+	}
+	digest.Stories = append(digest.Stories, stories...)
+	return digest, nil
+}
+
+// indexCloses returns the benchmark closes on a date. Today's come from
+// the live quotes; earlier days' from daily history.
+func indexCloses(date time.Time) ([]IndexClose, error) {
+	day := date.In(MarketLocation).Format("2006-01-02")
+	if day == time.Now().In(MarketLocation).Format("2006-01-02") {
+		indices, err := FetchMarketIndices()
+		if err != nil {
+			return nil, err
+		}
+		var closes []IndexClose
+		for _, index := range indices {
+			if index.UpdatedAt.In(MarketLocation).Format("2006-01-02") != day {
+				continue // the last quote is from an earlier session
+			}
+			closes = append(closes, IndexClose{
+				Symbol:     index.Symbol,
+				Name:       index.Name,
+				Close:      index.Price,
+				Change:     index.Change,
+				ChangePerc: index.ChangePerc,
+			})
+		}
+		if len(closes) == 0 {
+			return nil, fmt.Errorf("no trading on %s", day)
+		}
+		return closes, nil
+	}
+
+	chartRange := historyRange(date)
+	var closes []IndexClose
+	for _, symbol := range digestIndices {
+		bars, err := fetchDailyBars(symbol, chartRange)
+		if err != nil {
+			return nil, err
+		}
+		for i, bar := range bars {
+			if bar.Date != day {
+				continue
+			}
+			entry := IndexClose{Symbol: symbol, Name: getIndexName(symbol), Close: bar.Close}
+			if i > 0 {
+				entry.Change, entry.ChangePerc = calculateChange(bar.Close, bars[i-1].Close)
+			}
+			closes = append(closes, entry)
+		}
+	}
+	if len(closes) == 0 {
+		return nil, fmt.Errorf("no trading on %s", day)
+	}
+	return closes, nil
+}
+
+// historyRange is the shortest Yahoo chart range reaching back to date, with
+// room for the session before it
+func historyRange(date time.Time) string {
+	age := time.Since(date)
+	switch {
+	case age < 25*24*time.Hour:
+		return "1mo"
+	case age < 85*24*time.Hour:
+		return "3mo"
+	case age < 355*24*time.Hour:
+		return "1y"
+	case age < 5*360*24*time.Hour:
+		return "5y"
+	}
+	return "max"
+}
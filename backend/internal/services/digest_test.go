@@ -0,0 +1,134 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/database"
+)
+
+func TestParseDigestDate(t *testing.T) {
+	now := time.Now().In(MarketLocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, MarketLocation)
+
+	got, err := ParseDigestDate("")
+	if err != nil || !got.Equal(today) {
+		t.Errorf(`ParseDigestDate("") = %v, %v, want today %v`, got, err, today)
+	}
+	got, err = ParseDigestDate("2026-01-15")
+	if want := time.Date(2026, 1, 15, 0, 0, 0, 0, MarketLocation); err != nil || !got.Equal(want) {
+		t.Errorf("ParseDigestDate(2026-01-15) = %v, %v, want %v", got, err, want)
+	}
+	for _, value := range []string{"15-01-2026", "yesterday", today.AddDate(0, 0, 1).Format("2006-01-02")} {
+		if _, err := ParseDigestDate(value); err == nil {
+			t.Errorf("ParseDigestDate(%q) returned no error", value)
+		}
+	}
+}
+
+// seedIndexBars caches daily bars for the benchmark indices so closes are
+// read without fetching
+func seedIndexBars(t *testing.T, date time.Time, bars map[string][]PricePoint) {
+	t.Helper()
+	chartRange := historyRange(date)
+	priceCacheMu.Lock()
+	for symbol, points := range bars {
+		priceCache[symbol+"|"+chartRange] = cachedHistory{points: points, fetchedAt: time.Now()}
+	}
+	priceCacheMu.Unlock()
+	t.Cleanup(func() {
+		priceCacheMu.Lock()
+		for symbol := range bars {
+			delete(priceCache, symbol+"|"+chartRange)
+		}
+		priceCacheMu.Unlock()
+	})
+}
+
+func TestGetDigest(t *testing.T) {
+	openTestDB(t)
+	t.Cleanup(func() {
+		digestCacheMu.Lock()
+		digestCache = make(map[string]cachedDigest)
+		digestCacheMu.Unlock()
+	})
+
+	now := time.Now().In(MarketLocation)
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, MarketLocation).AddDate(0, 0, -10)
+	day := date.Format("2006-01-02")
+	previous := date.AddDate(0, 0, -1).Format("2006-01-02")
+	seedIndexBars(t, date, map[string][]PricePoint{
+		"^NSEI":  {{Date: previous, Close: 25000}, {Date: day, Close: 25250}},
+		"^BSESN": {{Date: previous, Close: 82000}, {Date: day, Close: 81590}},
+	})
+
+	for _, article := range storyArticles(date) {
+		article.URL = "https://example.com/" + strings.ReplaceAll(article.Title, " ", "-")
+		if _, err := database.InsertArticle(article); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Published the day before, and undated but stored on the digest day
+	previousDay := storyArticles(date.AddDate(0, 0, -1))[5]
+	previousDay.URL, previousDay.Title = "https://example.com/previous", "Gold slips from record"
+	if _, err := database.InsertArticle(previousDay); err != nil {
+		t.Fatal(err)
+	}
+	undated := storyArticles(date)[5]
+	undated.URL, undated.Title, undated.PublishedAt = "https://example.com/undated", "Silver follows gold to record", time.Time{}
+	id, err := database.InsertArticle(undated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := date.Add(15 * time.Hour).UTC().Format("2006-01-02 15:04:05")
+	if _, err := database.GetDB().Exec(`UPDATE articles SET created_at = ? WHERE id = ?`, stored, id); err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := GetDigest(date, 2)
+	if err != nil {
+		t.Fatalf("GetDigest() error = %v", err)
+	}
+	if digest.Date != day || digest.MarketError != "" {
+		t.Errorf("digest date %s, market error %q, want %s and none", digest.Date, digest.MarketError, day)
+	}
+	if len(digest.Markets) != 2 || digest.Markets[0].Close != 25250 || digest.Markets[0].Change != 250 || digest.Markets[1].ChangePerc != -0.5 {
+		t.Errorf("markets = %+v, want NIFTY up 250 and SENSEX down 0.5%%", digest.Markets)
+	}
+	if digest.Articles != 7 {
+		t.Errorf("digest counts %d articles, want the 6 published that day and the undated one", digest.Articles)
+	}
+	if len(digest.Stories) != 2 || len(digest.Stories[0].Sources) != 3 {
+		t.Fatalf("digest stories = %+v, want 2 led by the story with 3 sources", digest.Stories)
+	}
+
+	// Served from the cache, cut to the requested length
+	digestCacheMu.Lock()
+	built := digestCache[day].builtAt
+	digestCacheMu.Unlock()
+	digest, err = GetDigest(date, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digestCacheMu.Lock()
+	rebuilt := !digestCache[day].builtAt.Equal(built)
+	digestCacheMu.Unlock()
+	if rebuilt || len(digest.Stories) != 1 {
+		t.Errorf("second digest rebuilt %v with %d stories, want the cached digest with 1", rebuilt, len(digest.Stories))
+	}
+}
+
+func TestIndexClosesWithoutTrading(t *testing.T) {
+	now := time.Now().In(MarketLocation)
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, MarketLocation).AddDate(0, 0, -10)
+	holiday := date.AddDate(0, 0, 1).Format("2006-01-02")
+	seedIndexBars(t, date, map[string][]PricePoint{
+		"^NSEI":  {{Date: holiday, Close: 25000}},
+		"^BSESN": {{Date: holiday, Close: 82000}},
+	})
+
+	if _, err := indexCloses(date); err == nil || !strings.Contains(err.Error(), "no trading") {
+		t.Errorf("indexCloses() on a day without bars error = %v, want no trading", err)
+	}
+}
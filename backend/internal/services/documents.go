@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"stock-news-aggregator/internal/fetch"
+)
+
+// MaxDocumentBytes is the largest text or uploaded document that can be
+// summarized
+const MaxDocumentBytes = 5 << 20
+
+// ErrUnsupportedDocument means an upload is neither HTML nor plain text
+var ErrUnsupportedDocument = errors.New("unsupported document type, expected HTML or plain text; extract the text of PDFs first")
+
+// documentExtensions give the type of uploads sent without a useful
+// content type
+var documentExtensions = map[string]string{
+	".html": "text/html",
+	".htm":  "text/html",
+	".txt":  "text/plain",
+	".text": "text/plain",
+	".md":   "text/plain",
+}
+
+// DocumentText returns the text of an uploaded document: the article body
+// of an HTML page, or a plain-text file as is. The type comes from the
+// upload's content type, else its file extension, else its content.
+func DocumentText(filename, contentType string, data []byte) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType = documentExtensions[strings.ToLower(filepath.Ext(filename))]
+	}
+	if mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+
+	var text string
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		var err error
+		if text, err = fetch.MainText(&fetch.Page{ContentType: "text/html", Body: data}); err != nil {
+			return "", err
+		}
+	case "text/plain", "text/markdown":
+		text = string(data)
+	default:
+		return "", ErrUnsupportedDocument
+	}
+	if !utf8.ValidString(text) {
+		text = strings.ToValidUTF8(text, "�")
+	}
+	return text, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDocumentText(t *testing.T) {
+	const html = `<html><body><nav><p>Home | Markets | Companies | Latest news from the markets</p></nav>` +
+		`<article><p>Kotak Securities raised its target on Infosys to Rs 1,900 on strong deal wins.</p></article></body></html>`
+	const body = "Kotak Securities raised its target on Infosys to Rs 1,900 on strong deal wins."
+
+	tests := []struct {
+		name, filename, contentType, data string
+		want                              string
+		err                               error
+	}{
+		{"html", "note.html", "text/html; charset=utf-8", html, body, nil},
+		{"html by extension", "note.htm", "application/octet-stream", html, body, nil},
+		{"html by content", "note", "", html, body, nil},
+		{"plain text", "note.txt", "text/plain", body, body, nil},
+		{"markdown", "note.md", "", body, body, nil},
+		{"pdf", "note.pdf", "application/pdf", "%PDF-1.4", "", ErrUnsupportedDocument},
+		{"invalid utf-8", "note.txt", "text/plain", "Nifty\xff rose", "Nifty� rose", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DocumentText(tt.filename, tt.contentType, []byte(tt.data))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("DocumentText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/events"
+	"stock-news-aggregator/internal/models"
+)
+
+// classifierVersion is bumped whenever classification changes, so stored
+// event types are redone on the next start
+const classifierVersion = "1"
+
+// eventBackfillBatch is how many articles are classified per batch at startup
+const eventBackfillBatch = 500
+
+var (
+	classifier *events.Classifier
+	// eventsVersion identifies the classifier and rule set articles were
+	// classified with
+	eventsVersion string
+)
+
+// EventRulesFile returns the rule file path from EVENT_RULES_FILE, or "" for
+// the built-in rules
+func EventRulesFile() string {
+	return os.Getenv("EVENT_RULES_FILE")
+}
+
+// LoadEventRules reads the keyword rules articles are classified with
+func LoadEventRules(path string) error {
+	rules, fingerprint, err := events.LoadRules(path)
+	if err != nil {
+		return err
+	}
+	classifier = events.NewClassifier(rules)
+	eventsVersion = classifierVersion + ":" + fingerprint
+	if path != "" {
+		log.Printf("Loaded %d event rules from %s", len(rules.Rules), path)
+	}
+	return nil
+}
+
+// ClassifyArticleEvents finds and stores the event types of an article
+func ClassifyArticleEvents(article models.Article) error {
+	if classifier == nil {
+		return nil
+	}
+
+	var found []models.ArticleEvent
+	for _, event := range classifier.Classify(article.Title, article.Description+"\n"+article.Content) {
+		found = append(found, models.ArticleEvent{Type: event.Type, Score: event.Score})
+	}
+	return database.SaveArticleEvents(article.ID, found, eventsVersion)
+}
+
+// BackfillArticleEvents classifies every article stored before the current
+// rules were loaded
+func BackfillArticleEvents() error {
+	if classifier == nil {
+		return nil
+	}
+
+	var classified int
+	for {
+		articles, err := database.GetArticlesToClassify(eventsVersion, eventBackfillBatch)
+		if err != nil {
+			return err
+		}
+		if len(articles) == 0 {
+			break
+		}
+		for _, article := range articles {
+			if err := ClassifyArticleEvents(article); err != nil {
+				return err
+			}
+		}
+		classified += len(articles)
+	}
+	if classified > 0 {
+		log.Printf("Classified events for %d stored articles", classified)
+	}
+	return nil
+}
+
+// EventType is an event type articles can be filtered by
+type EventType struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// EventTypes lists the event types of the loaded rules
+func EventTypes() []EventType {
+	types := []EventType{}
+	if classifier == nil {
+		return types
+	}
+	for _, t := range classifier.Types() {
+		name, _ := classifier.Name(t)
+		types = append(types, EventType{Type: t, Name: name})
+	}
+	return types
+}
+
+// ParseEventType validates an event type such as "earnings"
+func ParseEventType(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if classifier != nil {
+		if _, ok := classifier.Name(value); ok {
+			return value, nil
+		}
+	}
+	var names []string
+	for _, t := range EventTypes() {
+		names = append(names, t.Type)
+	}
+	return "", fmt.Errorf("unknown event %q, expected one of %s", value, strings.Join(names, ", "))
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"log"
+	"strconv"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/facts"
+	"stock-news-aggregator/internal/models"
+)
+
+// factsVersion is bumped whenever extraction changes, so stored facts are
+// redone on the next start
+const factsVersion = "2"
+
+// factBackfillBatch is how many articles facts are extracted from per batch
+// at startup
+const factBackfillBatch = 500
+
+// maxArticleFacts caps how many facts are kept per article
+const maxArticleFacts = 50
+
+// ExtractArticleFacts finds and stores the numbers an article states. Each
+// fact is attributed to the company named in its sentence, or else to the
+// article's most confidently linked company.
+func ExtractArticleFacts(article models.Article) error {
+	var fallback string
+	if len(article.Tickers) > 0 {
+		fallback = article.Tickers[0].Symbol
+	}
+
+	var found []models.ArticleFact
+	seen := make(map[string]bool)
+	symbols := make(map[int]string)
+	for _, fact := range facts.Extract(article.Title + "\n" + article.Description + "\n" + article.Content) {
+		symbol, ok := symbols[fact.Sentence]
+		if !ok {
+			symbol = sentenceTicker(fact.Context, fallback)
+			symbols[fact.Sentence] = symbol
+		}
+
+		// Scraped content often repeats the headline and description
+		key := fact.Kind + "|" + fact.Label + "|" + fact.Period + "|" + fact.Unit + "|" + symbol + "|" +
+			strconv.FormatFloat(fact.Value, 'f', -1, 64)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		found = append(found, models.ArticleFact{
+			Kind:    fact.Kind,
+			Label:   fact.Label,
+			Period:  fact.Period,
+			Value:   fact.Value,
+			Amount:  fact.Amount,
+			Scale:   fact.Scale,
+			Unit:    fact.Unit,
+			Text:    fact.Text,
+			Context: fact.Context,
+			Symbol:  symbol,
+		})
+		if len(found) == maxArticleFacts {
+			break
+		}
+	}
+	return database.SaveArticleFacts(article.ID, found, factsVersion)
+}
+
+// sentenceTicker is the company a sentence is most confidently about
+func sentenceTicker(sentence, fallback string) string {
+	if linker != nil {
+		if links := linker.Link("", sentence); len(links) > 0 {
+			return links[0].Symbol
+		}
+	}
+	return fallback
+}
+
+// BackfillArticleFacts extracts facts from every article stored before the
+// current extractor
+func BackfillArticleFacts() error {
+	var extracted int
+	for {
+		articles, err := database.GetArticlesToExtract(factsVersion, factBackfillBatch)
+		if err != nil {
+			return err
+		}
+		if len(articles) == 0 {
+			break
+		}
+		for _, article := range articles {
+			if err := ExtractArticleFacts(article); err != nil {
+				return err
+			}
+		}
+		extracted += len(articles)
+	}
+	if extracted > 0 {
+		log.Printf("Extracted facts from %d stored articles", extracted)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"stock-news-aggregator/internal/llm"
+)
+
+// llmAlgorithm is the name the LLM summarizer is requested by. It falls
+// back to the default extractive algorithm when the model fails.
+const llmAlgorithm = "llm"
+
+// LoadLLMSummarizer registers the "llm" summarization algorithm when
+// LLM_BASE_URL is set. It reads:
+//
+//	LLM_BASE_URL        chat completions API base, e.g. http://10.0.0.5:8000/v1
+//	LLM_API_KEY         bearer token, if the server needs one
+//	LLM_MODEL           model name
+//	LLM_TIMEOUT         request timeout, e.g. 30s
+//	LLM_MAX_TOKENS      most tokens a summary may take
+//	LLM_CONTEXT_TOKENS  the model's context window; longer articles are cut
+//	LLM_TEMPERATURE     sampling temperature
+//	LLM_SYSTEM_PROMPT   system prompt replacing the default
+//	LLM_PROMPT_FILE     Go template file replacing the default prompt
+func LoadLLMSummarizer() error {
+	config, err := llmConfig()
+	if err != nil || config.BaseURL == "" {
+		return err
+	}
+
+	var prompt string
+	if path := os.Getenv("LLM_PROMPT_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read LLM prompt: %v", err)
+		}
+		prompt = string(data)
+	}
+	s, err := llm.NewSummarizer(config, os.Getenv("LLM_SYSTEM_PROMPT"), prompt)
+	if err != nil {
+		return err
+	}
+	registerAlgorithm(s)
+	log.Printf("LLM summarizer enabled with model %q at %s", config.Model, config.BaseURL)
+	return nil
+}
+
+func llmConfig() (llm.Config, error) {
+	config := llm.DefaultConfig()
+	config.BaseURL = os.Getenv("LLM_BASE_URL")
+	config.APIKey = os.Getenv("LLM_API_KEY")
+	config.Model = os.Getenv("LLM_MODEL")
+
+	if value := os.Getenv("LLM_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return config, fmt.Errorf("invalid LLM_TIMEOUT %q", value)
+		}
+		config.Timeout = timeout
+	}
+	for name, target := range map[string]*int{
+		"LLM_MAX_TOKENS":     &config.MaxTokens,
+		"LLM_CONTEXT_TOKENS": &config.ContextTokens,
+	} {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return config, fmt.Errorf("invalid %s %q", name, value)
+			}
+			*target = n
+		}
+	}
+	if value := os.Getenv("LLM_TEMPERATURE"); value != "" {
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil || temperature < 0 {
+			return config, fmt.Errorf("invalid LLM_TEMPERATURE %q", value)
+		}
+		config.Temperature = temperature
+	}
+	return config, nil
+}
@@ -7,9 +7,21 @@ import (
 	"log"
 	"math"
 	"net/http"
+	neturl "net/url"
 	"time"
 )
 
+// MarketLocation is the time zone Indian exchanges trade in
+var MarketLocation = loadMarketLocation()
+
+func loadMarketLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+	return loc
+}
+
 type MarketIndex struct {
 	Symbol     string    `json:"symbol"`
 	Name       string    `json:"name"`
@@ -20,25 +32,31 @@ type MarketIndex struct {
 	IsDelayed  bool      `json:"isDelayed"`
 }
 
+// YahooChartResult is one symbol's data from the Yahoo chart endpoint
+type YahooChartResult struct {
+	Meta struct {
+		Symbol             string  `json:"symbol"`
+		RegularMarketPrice float64 `json:"regularMarketPrice"`
+		ChartPreviousClose float64 `json:"chartPreviousClose"`
+		PreviousClose      float64 `json:"previousClose"`
+		RegularMarketTime  int64   `json:"regularMarketTime"`
+	} `json:"meta"`
+	Timestamp  []int64 `json:"timestamp"`
+	Indicators struct {
+		Quote []struct {
+			Close  []float64 `json:"close"`
+			Open   []float64 `json:"open"`
+			High   []float64 `json:"high"`
+			Low    []float64 `json:"low"`
+			Volume []int64   `json:"volume"`
+		} `json:"quote"`
+	} `json:"indicators"`
+}
+
 type YahooResponse struct {
 	Chart struct {
-		Result []struct {
-			Meta struct {
-				Symbol             string  `json:"symbol"`
-				RegularMarketPrice float64 `json:"regularMarketPrice"`
-				ChartPreviousClose float64 `json:"chartPreviousClose"`
-				PreviousClose      float64 `json:"previousClose"`
-				RegularMarketTime  int64   `json:"regularMarketTime"`
-			} `json:"meta"`
-			Timestamp []int64   `json:"timestamp"`
-			Indicators struct {
-				Quote []struct {
-					Close []float64 `json:"close"`
-					Open  []float64 `json:"open"`
-				} `json:"quote"`
-			} `json:"indicators"`
-		} `json:"result"`
-		Error *struct {
+		Result []YahooChartResult `json:"result"`
+		Error  *struct {
 			Code        string `json:"code"`
 			Description string `json:"description"`
 		} `json:"error"`
@@ -74,9 +92,11 @@ func calculateChange(currentPrice, previousClose float64) (change, changePerc fl
 	return change, changePerc
 }
 
-func fetchSingleIndex(symbol string) (*MarketIndex, error) {
-	// Using the chart endpoint to get both current and historical data
-	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=1d&range=2d", symbol)
+// fetchYahooChart fetches daily bars for a Yahoo symbol over a range such as
+// "2d" or "3mo"
+func fetchYahooChart(symbol, chartRange string) (*YahooChartResult, error) {
+	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s?interval=1d&range=%s",
+		neturl.PathEscape(symbol), neturl.QueryEscape(chartRange))
 	
 	client := &http.Client{
 		Timeout: 10 * time.Second,
@@ -123,7 +143,15 @@ func fetchSingleIndex(symbol string) (*MarketIndex, error) {
 		return nil, fmt.Errorf("no data received for symbol %s", symbol)
 	}
 
-	result := yahooResp.Chart.Result[0]
+	return &yahooResp.Chart.Result[0], nil
+}
+
+func fetchSingleIndex(symbol string) (*MarketIndex, error) {
+	// Using the chart endpoint to get both current and historical data
+	result, err := fetchYahooChart(symbol, "2d")
+	if err != nil {
+		return nil, err
+	}
 	meta := result.Meta
 	
 	// Get current price and calculate changes
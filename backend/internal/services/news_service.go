@@ -7,87 +7,176 @@ import (
 	"time"
 	"stock-news-aggregator/internal/database"
 	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/search"
 )
 
 func FetchAllNews() ([]models.Article, error) {
 	// This function will now fetch from the database instead of scraping directly
-	articles, _, err := database.GetArticles(1, 1000, "") // Large page size to get all articles
+	articles, _, err := database.GetArticles(1, 1000, database.ArticleFilter{}) // Large page size to get all articles
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert database.Article to models.Article
-	var modelArticles []models.Article
-	for _, article := range articles {
-		modelArticles = append(modelArticles, models.Article{
-			Title:       article.Title,
-			URL:         article.URL,
-			Source:      models.Source{Name: article.Source},
-			Content:     article.Content,
-			PublishedAt: article.PublishedAt,
-		})
-	}
+	return articles, nil
+}
+
+// GetArticle returns a single stored article by ID
+func GetArticle(id int64) (*models.Article, error) {
+	return database.GetArticleByID(id)
+}
+
+// ParseQuery parses an advanced search query, resolving source aliases and
+// dates in market time. Syntax errors are returned as *search.ParseError.
+func ParseQuery(q string) (search.Node, error) {
+	return search.Parse(q, search.Options{
+		Now:           time.Now(),
+		Location:      MarketLocation,
+		ResolveSource: CanonicalSource,
+		ResolveTicker: ResolveTicker,
+	})
+}
 
-	return modelArticles, nil
+// GetNewsFacets counts the articles matching the filter per source, per
+// publish day in market time, and per section
+func GetNewsFacets(filter database.ArticleFilter) (*database.Facets, error) {
+	return database.GetFacets(filter, MarketLocation)
 }
 
-func GetNewsFromDB(page, pageSize int, search string) ([]models.Article, int, error) {
-	// Get a larger set of articles to allow for shuffling
-	multiplier := 3 // Get 3x the requested page size to ensure good distribution
-	articles, totalCount, err := database.GetArticles(page, pageSize*multiplier, search)
+// BalanceOptions controls how GetNewsFromDB interleaves sources
+type BalanceOptions struct {
+	Disabled bool  // return articles in plain filter order
+	Seed     int64 // permutes the order sources take turns in; 0 keeps them alphabetical
+}
+
+// GetNewsFromDB returns one page of articles matching the filter. Unless
+// balancing is disabled, sources take turns across the whole result set,
+// one article each per round, so the order is stable between requests and
+// every article appears on exactly one page. The page is worked out from the
+// article counts per source, so only its own articles are loaded.
+func GetNewsFromDB(page, pageSize int, filter database.ArticleFilter, balance BalanceOptions) ([]models.Article, int, error) {
+	if balance.Disabled {
+		articles, total, err := database.GetArticles(page, pageSize, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		return articles, total, attachSummaries(articles)
+	}
+
+	counts, err := database.CountArticlesBySource(filter)
 	if err != nil {
 		return nil, 0, err
 	}
+	total := 0
+	for _, count := range counts {
+		total += count.Count
+	}
 
-	// Group articles by source
-	sourceGroups := make(map[string][]database.Article)
-	sources := make([]string, 0)
-	for _, article := range articles {
-		if _, exists := sourceGroups[article.Source]; !exists {
-			sources = append(sources, article.Source)
-		}
-		sourceGroups[article.Source] = append(sourceGroups[article.Source], article)
+	start := (page - 1) * pageSize
+	if start >= total {
+		return nil, total, nil
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
 	}
 
-	// Sort sources to ensure consistent ordering
-	sort.Strings(sources)
+	ids, err := interleavedPage(counts, balance.Seed, start, end, func(source string, offset, limit int) ([]int64, error) {
+		sourceFilter := filter
+		sourceFilter.Sources = []string{source}
+		return database.GetArticleIDs(sourceFilter, offset, limit)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// Initialize random number generator with current time
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	articles, err := database.GetArticlesByIDs(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	return articles, total, attachSummaries(articles)
+}
 
-	// Shuffle articles within each source group
-	for _, articles := range sourceGroups {
-		rnd.Shuffle(len(articles), func(i, j int) {
-			articles[i], articles[j] = articles[j], articles[i]
-		})
+// interleavedPage returns positions [start, end) of the order in which
+// sources with these article counts take turns, each keeping its own order,
+// loading only the articles on the page from each source with load
+func interleavedPage(counts []database.FacetCount, seed int64, start, end int, load func(source string, offset, limit int) ([]int64, error)) ([]int64, error) {
+	sources := make([]string, len(counts))
+	sizes := make(map[string]int, len(counts))
+	longest := 0
+	for i, count := range counts {
+		sources[i] = count.Value
+		sizes[count.Value] = count.Count
+		if count.Count > longest {
+			longest = count.Count
+		}
 	}
+	sources = sourceTurns(sources, seed)
 
-	// Create a balanced selection of articles from different sources
-	var selectedArticles []database.Article
-	sourceIndex := 0
-	for len(selectedArticles) < pageSize && len(selectedArticles) < len(articles) {
-		source := sources[sourceIndex%len(sources)]
-		if articles := sourceGroups[source]; len(articles) > 0 {
-			selectedArticles = append(selectedArticles, articles[0])
-			sourceGroups[source] = articles[1:] // Remove the selected article
+	// Round r holds each source's r-th article, from position before(r)
+	before := func(round int) int {
+		n := 0
+		for _, size := range sizes {
+			if size < round {
+				n += size
+			} else {
+				n += round
+			}
+		}
+		return n
+	}
+	first := sort.Search(longest, func(r int) bool { return before(r+1) > start })
+	last := sort.Search(longest, func(r int) bool { return before(r+1) >= end })
+
+	groups := make(map[string][]int64, len(sources))
+	for _, source := range sources {
+		size := sizes[source]
+		if size <= first {
+			continue
+		}
+		limit := last - first + 1
+		if size-first < limit {
+			limit = size - first
+		}
+		ids, err := load(source, first, limit)
+		if err != nil {
+			return nil, err
+		}
+		groups[source] = ids
+	}
+
+	var order []int64
+	for round := 0; round <= last-first; round++ {
+		for _, source := range sources {
+			if group := groups[source]; round < len(group) {
+				order = append(order, group[round])
+			}
 		}
-		sourceIndex++
-	}
-
-	// Convert database.Article to models.Article
-	var modelArticles []models.Article
-	for _, article := range selectedArticles {
-		modelArticles = append(modelArticles, models.Article{
-			Title:       article.Title,
-			URL:         article.URL,
-			Source:      models.Source{Name: article.Source},
-			Content:     article.Content,
-			Description: article.Description,
-			PublishedAt: article.PublishedAt,
-		})
 	}
 
-	return modelArticles, totalCount, nil
+	// Articles deleted since counting can leave the page short
+	skip := start - before(first)
+	if skip > len(order) {
+		return nil, nil
+	}
+	order = order[skip:]
+	if len(order) > end-start {
+		order = order[:end-start]
+	}
+	return order, nil
+}
+
+// sourceTurns sorts sources into the order they take turns in:
+// alphabetical, or permuted by seed when it is non-zero
+func sourceTurns(sources []string, seed int64) []string {
+	// Sort sources to ensure consistent ordering
+	sort.Strings(sources)
+	if seed != 0 {
+		rnd := rand.New(rand.NewSource(seed))
+		rnd.Shuffle(len(sources), func(i, j int) {
+			sources[i], sources[j] = sources[j], sources[i]
+		})
+	}
+	return sources
 }
 
 // ScrapeAndStoreNews performs the scraping of news articles and stores them in the database
@@ -187,6 +276,7 @@ func ScrapeAndStoreNews() error {
 	// Store all articles in the database
 	var totalStored int
 	var totalSkipped int
+	var newArticleIDs []int64
 
 	storeArticles := func(articles []models.Article, source string) {
 		if articles == nil {
@@ -200,17 +290,27 @@ func ScrapeAndStoreNews() error {
 			}
 
 			if !exists {
-				err = database.InsertArticle(
-					article.Title,
-					article.URL,
-					article.Source.Name,
-					article.Content,
-					article.Description,
-					article.PublishedAt,
-				)
+				id, err := database.InsertArticle(article)
 				if err != nil {
 					log.Printf("Error storing article from %s: %v", source, err)
-				} else {
+				} else if id != 0 {
+					article.ID = id
+					article.CreatedAt = time.Now()
+					if article.Tickers, err = LinkArticleTickers(article); err != nil {
+						log.Printf("Error linking companies for article %d: %v", id, err)
+					}
+					if err := ScoreArticleSentiment(article); err != nil {
+						log.Printf("Error scoring sentiment for article %d: %v", id, err)
+					}
+					if err := ClassifyArticleEvents(article); err != nil {
+						log.Printf("Error classifying events for article %d: %v", id, err)
+					}
+					if err := ExtractArticleFacts(article); err != nil {
+						log.Printf("Error extracting facts from article %d: %v", id, err)
+					}
+					IndexRelatedArticle(article)
+					TrackTrends(article)
+					newArticleIDs = append(newArticleIDs, id)
 					totalStored++
 					log.Printf("Stored new article from %s: %s", source, article.Title)
 				}
@@ -229,5 +329,18 @@ func ScrapeAndStoreNews() error {
 	storeArticles(indiaTodayArticles, "India Today")
 
 	log.Printf("Scraping completed. Total articles stored: %d, skipped (already exists): %d", totalStored, totalSkipped)
+
+	// Raise alerts for saved searches matching the new articles
+	if err := MatchSavedSearches(newArticleIDs); err != nil {
+		log.Printf("Error matching saved searches: %v", err)
+	}
+
+	// Summarize the new articles in the background for listings
+	QueueArticleSummaries(newArticleIDs)
+
+	// Pick up the new headlines for search suggestions
+	if err := RefreshSuggestions(); err != nil {
+		log.Printf("Error refreshing search suggestions: %v", err)
+	}
 	return nil
 } 
\ No newline at end of file
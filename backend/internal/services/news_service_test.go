@@ -0,0 +1,149 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"stock-news-aggregator/internal/database"
+)
+
+// articleKey identifies an article and its source
+type articleKey struct {
+	ID     int64
+	Source string
+}
+
+// interleaveBySource round-robins the keys across their sources, keeping each
+// source's own order. Sources take turns alphabetically, or in an order
+// permuted by seed when it is non-zero. The result contains every ID exactly
+// once and depends only on its inputs. It is the order interleavedPage pages
+// through, built in full.
+func interleaveBySource(keys []articleKey, seed int64) []int64 {
+	// Group article IDs by source
+	sourceGroups := make(map[string][]int64)
+	sources := make([]string, 0)
+	for _, key := range keys {
+		if _, exists := sourceGroups[key.Source]; !exists {
+			sources = append(sources, key.Source)
+		}
+		sourceGroups[key.Source] = append(sourceGroups[key.Source], key.ID)
+	}
+	sources = sourceTurns(sources, seed)
+
+	// Take one article from each source per round until all are used
+	order := make([]int64, 0, len(keys))
+	for round := 0; len(order) < len(keys); round++ {
+		for _, source := range sources {
+			if group := sourceGroups[source]; round < len(group) {
+				order = append(order, group[round])
+			}
+		}
+	}
+	return order
+}
+
+func TestInterleaveBySource(t *testing.T) {
+	keys := []articleKey{
+		{ID: 1, Source: "Livemint"},
+		{ID: 2, Source: "Livemint"},
+		{ID: 3, Source: "Economic Times"},
+		{ID: 4, Source: "Livemint"},
+		{ID: 5, Source: "Business Standard"},
+		{ID: 6, Source: "Economic Times"},
+		{ID: 7, Source: "Livemint"},
+	}
+
+	got := interleaveBySource(keys, 0)
+	want := []int64{5, 3, 1, 6, 2, 4, 7}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("interleaveBySource() = %v, want %v", got, want)
+	}
+
+	t.Run("every article exactly once", func(t *testing.T) {
+		for _, seed := range []int64{0, 1, 42, -7} {
+			seen := make(map[int64]int)
+			for _, id := range interleaveBySource(keys, seed) {
+				seen[id]++
+			}
+			if len(seen) != len(keys) {
+				t.Errorf("seed %d: got %d distinct IDs, want %d", seed, len(seen), len(keys))
+			}
+			for id, count := range seen {
+				if count != 1 {
+					t.Errorf("seed %d: ID %d appeared %d times", seed, id, count)
+				}
+			}
+		}
+	})
+
+	t.Run("deterministic for a seed", func(t *testing.T) {
+		first := interleaveBySource(keys, 42)
+		second := interleaveBySource(keys, 42)
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("same seed gave %v and %v", first, second)
+		}
+	})
+
+	t.Run("pages do not overlap", func(t *testing.T) {
+		order := interleaveBySource(keys, 3)
+		pageSize := 3
+		seen := make(map[int64]bool)
+		for start := 0; start < len(order); start += pageSize {
+			end := start + pageSize
+			if end > len(order) {
+				end = len(order)
+			}
+			for _, id := range order[start:end] {
+				if seen[id] {
+					t.Errorf("ID %d appeared on more than one page", id)
+				}
+				seen[id] = true
+			}
+		}
+	})
+}
+
+func TestInterleavedPage(t *testing.T) {
+	var keys []articleKey
+	groups := make(map[string][]int64)
+	for i, source := range []string{"Livemint", "Livemint", "Economic Times", "Livemint", "Business Standard",
+		"Economic Times", "Livemint", "Groww", "Livemint", "Economic Times", "Livemint", "Livemint"} {
+		id := int64(i + 1)
+		keys = append(keys, articleKey{ID: id, Source: source})
+		groups[source] = append(groups[source], id)
+	}
+	var counts []database.FacetCount
+	for _, source := range []string{"Business Standard", "Economic Times", "Groww", "Livemint"} {
+		counts = append(counts, database.FacetCount{Value: source, Count: len(groups[source])})
+	}
+
+	// Every page matches the same slice of the full interleaved order, and
+	// no more of a source is loaded than fits on the page
+	for _, seed := range []int64{0, 1, 42} {
+		order := interleaveBySource(keys, seed)
+		for pageSize := 1; pageSize <= len(keys); pageSize++ {
+			for start := 0; start < len(keys); start += pageSize {
+				end := start + pageSize
+				if end > len(keys) {
+					end = len(keys)
+				}
+				got, err := interleavedPage(counts, seed, start, end, func(source string, offset, limit int) ([]int64, error) {
+					if limit > pageSize {
+						t.Errorf("seed %d, page [%d, %d): loaded %d articles from %s", seed, start, end, limit, source)
+					}
+					group := groups[source]
+					if offset+limit > len(group) {
+						limit = len(group) - offset
+					}
+					return group[offset : offset+limit], nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, order[start:end]) {
+					t.Errorf("seed %d, page [%d, %d) = %v, want %v", seed, start, end, got, order[start:end])
+				}
+			}
+		}
+	}
+}
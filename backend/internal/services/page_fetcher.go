@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"stock-news-aggregator/internal/fetch"
+)
+
+var (
+	pageFetcherOnce sync.Once
+	pageFetcher     *fetch.Fetcher
+)
+
+// summarizeDomains are the hosts pages may be fetched from for summarizing:
+// the news sources plus any listed in SUMMARIZE_ALLOWED_DOMAINS
+func summarizeDomains() []string {
+	domains := append([]string{}, sourceDomains...)
+	for _, domain := range strings.Split(os.Getenv("SUMMARIZE_ALLOWED_DOMAINS"), ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// getPageFetcher creates the fetcher on first use, after the environment has
+// been loaded
+func getPageFetcher() *fetch.Fetcher {
+	pageFetcherOnce.Do(func() {
+		pageFetcher = fetch.New(fetch.DefaultConfig(summarizeDomains()))
+	})
+	return pageFetcher
+}
+
+// fetchArticleText downloads an allowed news page and returns the text of
+// its article body
+func fetchArticleText(url string) (string, error) {
+	page, err := getPageFetcher().Fetch(context.Background(), url)
+	if err != nil {
+		return "", err
+	}
+	return fetch.MainText(page)
+}
+
+// IsFetchRefused reports whether err means a page was not fetched because
+// the URL or the response was not allowed, rather than because the site
+// failed
+func IsFetchRefused(err error) bool {
+	for _, refusal := range []error{fetch.ErrInvalidURL, fetch.ErrHostNotAllowed, fetch.ErrPrivateAddress,
+		fetch.ErrTooLarge, fetch.ErrContentType, fetch.ErrTooManyHops} {
+		if errors.Is(err, refusal) {
+			return true
+		}
+	}
+	return false
+}
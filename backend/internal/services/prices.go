@@ -0,0 +1,105 @@
+package services
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// PricePoint is one trading day's bar for a stock
+type PricePoint struct {
+	Date       string  `json:"date"` // YYYY-MM-DD in market time
+	Open       float64 `json:"open"`
+	High       float64 `json:"high"`
+	Low        float64 `json:"low"`
+	Close      float64 `json:"close"`
+	Volume     int64   `json:"volume"`
+	ChangePerc float64 `json:"changePercentage"` // close against the previous close
+}
+
+// priceCacheTTL is how long daily history is reused before refetching
+const priceCacheTTL = 15 * time.Minute
+
+type cachedHistory struct {
+	points    []PricePoint
+	fetchedAt time.Time
+}
+
+var (
+	priceCacheMu sync.Mutex
+	priceCache   = make(map[string]cachedHistory)
+)
+
+// yahooSymbol is the Yahoo Finance symbol for an NSE-listed stock
+func yahooSymbol(symbol string) string {
+	return symbol + ".NS"
+}
+
+// FetchDailyHistory returns a stock's daily bars over a Yahoo chart range
+// such as "3mo", oldest first. Results are cached for a few minutes.
+func FetchDailyHistory(symbol, chartRange string) ([]PricePoint, error) {
+	return fetchDailyBars(yahooSymbol(symbol), chartRange)
+}
+
+// fetchDailyBars returns the daily bars of a Yahoo symbol, such as a stock's
+// or "^NSEI", through the cache
+func fetchDailyBars(symbol, chartRange string) ([]PricePoint, error) {
+	key := symbol + "|" + chartRange
+
+	priceCacheMu.Lock()
+	cached, ok := priceCache[key]
+	priceCacheMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < priceCacheTTL {
+		return cached.points, nil
+	}
+
+	result, err := fetchYahooChart(symbol, chartRange)
+	if err != nil {
+		return nil, err
+	}
+	points := dailyBars(result)
+
+	priceCacheMu.Lock()
+	priceCache[key] = cachedHistory{points: points, fetchedAt: time.Now()}
+	priceCacheMu.Unlock()
+	return points, nil
+}
+
+// dailyBars converts a chart result into daily bars, skipping days Yahoo
+// has no close for
+func dailyBars(result *YahooChartResult) []PricePoint {
+	points := []PricePoint{}
+	if len(result.Indicators.Quote) == 0 {
+		return points
+	}
+	quote := result.Indicators.Quote[0]
+	value := func(values []float64, i int) float64 {
+		if i < len(values) && isValidNumber(values[i]) {
+			return math.Round(values[i]*100) / 100
+		}
+		return 0
+	}
+
+	var previousClose float64
+	for i, ts := range result.Timestamp {
+		point := PricePoint{
+			Date:  time.Unix(ts, 0).In(MarketLocation).Format("2006-01-02"),
+			Open:  value(quote.Open, i),
+			High:  value(quote.High, i),
+			Low:   value(quote.Low, i),
+			Close: value(quote.Close, i),
+		}
+		if point.Close == 0 {
+			continue
+		}
+		if i < len(quote.Volume) {
+			point.Volume = quote.Volume[i]
+		}
+		if previousClose != 0 {
+			_, point.ChangePerc = calculateChange(point.Close, previousClose)
+		}
+		previousClose = point.Close
+		points = append(points, point)
+	}
+	return points
+}
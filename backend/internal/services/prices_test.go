@@ -0,0 +1,40 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDailyBars(t *testing.T) {
+	// Yahoo reports days without trades as nulls
+	body := `{"chart":{"result":[{
+		"meta":{"symbol":"INFY.NS"},
+		"timestamp":[1749440700,1749527100,1749613500],
+		"indicators":{"quote":[{
+			"open":[1550.5,null,1570],
+			"high":[1565.25,null,1590.4],
+			"low":[1540,null,1561.1],
+			"close":[1560,null,1585.6],
+			"volume":[1200000,null,950000]
+		}]}
+	}]}}`
+
+	var resp YahooResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+	got := dailyBars(&resp.Chart.Result[0])
+	want := []PricePoint{
+		{Date: "2025-06-09", Open: 1550.5, High: 1565.25, Low: 1540, Close: 1560, Volume: 1200000},
+		{Date: "2025-06-11", Open: 1570, High: 1590.4, Low: 1561.1, Close: 1585.6, Volume: 950000, ChangePerc: 1.64},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dailyBars() = %+v, want %+v", got, want)
+	}
+
+	empty := dailyBars(&YahooChartResult{Timestamp: []int64{1749440700}})
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("dailyBars() without quotes = %#v, want an empty slice", empty)
+	}
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+)
+
+// titleWeight counts title words more than body words, since headlines name
+// the story's subject
+const titleWeight = 2
+
+// RelatedArticle is a stored article with its similarity to another article
+type RelatedArticle struct {
+	Article models.ArticleDTO `json:"article"`
+	Score   float64           `json:"score"`
+}
+
+// tfidfIndex holds term frequencies for every stored article. Weights are
+// computed at query time so documents can be added without re-weighting the
+// rest of the index.
+type tfidfIndex struct {
+	mu       sync.RWMutex
+	docs     map[int64]map[string]float64 // article ID -> term -> frequency
+	postings map[string]map[int64]float64 // term -> article ID -> frequency
+	pending  map[int64]map[string]float64 // articles added during a rebuild, nil otherwise
+}
+
+var relatedIndex = newTFIDFIndex()
+
+// relatedRebuildMu keeps rebuilds of the related index from overlapping
+var relatedRebuildMu sync.Mutex
+
+func newTFIDFIndex() *tfidfIndex {
+	return &tfidfIndex{
+		docs:     make(map[int64]map[string]float64),
+		postings: make(map[string]map[int64]float64),
+	}
+}
+
+// articleTerms counts the terms in an article's title, description and content
+func articleTerms(article models.Article) map[string]float64 {
+	terms := make(map[string]float64)
+	for _, term := range contentTerms(article.Title) {
+		terms[term] += titleWeight
+	}
+	for _, term := range contentTerms(article.Description + " " + article.Content) {
+		terms[term]++
+	}
+	return terms
+}
+
+// add indexes an article, replacing any previous version of it
+func (idx *tfidfIndex) add(id int64, terms map[string]float64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.addLocked(id, terms)
+	if idx.pending != nil {
+		idx.pending[id] = terms
+	}
+}
+
+func (idx *tfidfIndex) addLocked(id int64, terms map[string]float64) {
+	idx.removeLocked(id)
+	idx.docs[id] = terms
+	for term, tf := range terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[int64]float64)
+		}
+		idx.postings[term][id] = tf
+	}
+}
+
+// beginRebuild starts recording added articles, so a rebuilt index that
+// missed them can have them replayed by replace
+func (idx *tfidfIndex) beginRebuild() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.pending = make(map[int64]map[string]float64)
+}
+
+// cancelRebuild stops recording added articles, keeping the current index
+func (idx *tfidfIndex) cancelRebuild() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.pending = nil
+}
+
+// replace swaps in the contents of a rebuilt index, so lookups see either
+// the old index or the new one and never a partly built one. Articles added
+// since beginRebuild are replayed into it first.
+func (idx *tfidfIndex) replace(rebuilt *tfidfIndex) {
+	rebuilt.mu.Lock()
+	docs, postings := rebuilt.docs, rebuilt.postings
+	rebuilt.mu.Unlock()
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.docs, idx.postings = docs, postings
+	for id, terms := range idx.pending {
+		idx.addLocked(id, terms)
+	}
+	idx.pending = nil
+}
+
+func (idx *tfidfIndex) removeLocked(id int64) {
+	for term := range idx.docs[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// idf is the smoothed inverse document frequency of a term
+func (idx *tfidfIndex) idf(term string) float64 {
+	n := float64(len(idx.docs))
+	df := float64(len(idx.postings[term]))
+	return math.Log((n+1)/(df+1)) + 1
+}
+
+// weight is the sublinear TF-IDF weight of a term in a document
+func (idx *tfidfIndex) weight(term string, tf float64) float64 {
+	return (1 + math.Log(tf)) * idx.idf(term)
+}
+
+func (idx *tfidfIndex) norm(terms map[string]float64) float64 {
+	var sum float64
+	for term, tf := range terms {
+		w := idx.weight(term, tf)
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// similar returns the n documents with the highest cosine similarity to the
+// given terms, excluding the document itself
+func (idx *tfidfIndex) similar(id int64, terms map[string]float64, n int) []scoredID {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	queryNorm := idx.norm(terms)
+	if queryNorm == 0 {
+		return nil
+	}
+
+	// Accumulate dot products over the postings of the query's terms only
+	dots := make(map[int64]float64)
+	for term, tf := range terms {
+		qw := idx.weight(term, tf)
+		for docID, docTF := range idx.postings[term] {
+			if docID != id {
+				dots[docID] += qw * idx.weight(term, docTF)
+			}
+		}
+	}
+
+	scored := make([]scoredID, 0, len(dots))
+	for docID, dot := range dots {
+		if docNorm := idx.norm(idx.docs[docID]); docNorm > 0 {
+			scored = append(scored, scoredID{id: docID, score: dot / (queryNorm * docNorm)})
+		}
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].id > scored[j].id
+	})
+	if len(scored) > n {
+		scored = scored[:n]
+	}
+	return scored
+}
+
+// unitVectors weights each document's terms by TF-IDF and scales them to
+// unit length, so the cosine similarity of two documents is the dot product
+// of their vectors. Documents without terms get an empty vector.
+func (idx *tfidfIndex) unitVectors(docs []map[string]float64) []map[string]float64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	vectors := make([]map[string]float64, len(docs))
+	for i, terms := range docs {
+		vectors[i] = make(map[string]float64, len(terms))
+		norm := idx.norm(terms)
+		if norm == 0 {
+			continue
+		}
+		for term, tf := range terms {
+			vectors[i][term] = idx.weight(term, tf) / norm
+		}
+	}
+	return vectors
+}
+
+// dot is the dot product of two term vectors
+func dot(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var sum float64
+	for term, w := range a {
+		sum += w * b[term]
+	}
+	return sum
+}
+
+func (idx *tfidfIndex) lookup(id int64) (map[string]float64, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	terms, ok := idx.docs[id]
+	return terms, ok
+}
+
+type scoredID struct {
+	id    int64
+	score float64
+}
+
+// BuildRelatedIndex indexes every stored article for related-article
+// lookups. The index is built aside and swapped in whole, so lookups keep
+// using the previous one meanwhile.
+func BuildRelatedIndex() error {
+	relatedRebuildMu.Lock()
+	defer relatedRebuildMu.Unlock()
+
+	relatedIndex.beginRebuild()
+	rebuilt := newTFIDFIndex()
+	var count int
+	err := database.ScanArticles(func(article models.Article) error {
+		rebuilt.add(article.ID, articleTerms(article))
+		count++
+		return nil
+	})
+	if err != nil {
+		relatedIndex.cancelRebuild()
+		return err
+	}
+	relatedIndex.replace(rebuilt)
+	log.Printf("Indexed %d articles for related-article lookups", count)
+	return nil
+}
+
+// IndexRelatedArticle adds a newly stored article to the related-article index
+func IndexRelatedArticle(article models.Article) {
+	relatedIndex.add(article.ID, articleTerms(article))
+}
+
+// GetRelatedArticles returns the n stored articles most similar to the given one
+func GetRelatedArticles(id int64, n int) ([]RelatedArticle, error) {
+	terms, ok := relatedIndex.lookup(id)
+	if !ok {
+		article, err := database.GetArticleByID(id)
+		if err != nil {
+			return nil, err
+		}
+		terms = articleTerms(*article)
+		relatedIndex.add(id, terms)
+	}
+
+	scored := relatedIndex.similar(id, terms, n)
+	ids := make([]int64, len(scored))
+	scores := make(map[int64]float64, len(scored))
+	for i, s := range scored {
+		ids[i] = s.id
+		scores[s.id] = s.score
+	}
+
+	articles, err := database.GetArticlesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]RelatedArticle, 0, len(articles))
+	for _, article := range articles {
+		related = append(related, RelatedArticle{
+			Article: models.NewArticleDTO(article),
+			Score:   math.Round(scores[article.ID]*1000) / 1000,
+		})
+	}
+	return related, nil
+}
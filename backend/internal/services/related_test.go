@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func TestTFIDFIndexSimilar(t *testing.T) {
+	idx := newTFIDFIndex()
+	articles := []models.Article{
+		{ID: 1, Title: "RBI keeps repo rate unchanged", Description: "The central bank held the repo rate at 6.5% citing inflation"},
+		{ID: 2, Title: "Repo rate pause: what RBI policy means for home loans", Description: "Borrowers see no change in EMIs after the repo rate hold"},
+		{ID: 3, Title: "Tata Motors shares jump on strong JLR sales", Description: "JLR volumes rose in the quarter"},
+		{ID: 4, Title: "Inflation cools, RBI may cut rates", Description: "Economists expect the repo rate to fall"},
+	}
+	for _, a := range articles {
+		idx.add(a.ID, articleTerms(a))
+	}
+
+	got := idx.similar(1, idx.docs[1], 3)
+	if len(got) != 2 {
+		t.Fatalf("similar(1) returned %d articles, want 2 sharing terms: %+v", len(got), got)
+	}
+	for _, s := range got {
+		if s.id == 1 || s.id == 3 {
+			t.Errorf("similar(1) returned article %d", s.id)
+		}
+	}
+	if got[0].score < got[1].score {
+		t.Errorf("similar(1) not ordered by score: %+v", got)
+	}
+
+	// Re-adding an article replaces its terms rather than accumulating them
+	idx.add(3, articleTerms(models.Article{ID: 3, Title: "RBI repo rate"}))
+	if len(idx.postings["jlr"]) != 0 {
+		t.Errorf("postings for replaced terms were not removed: %v", idx.postings["jlr"])
+	}
+	if got := idx.similar(1, idx.docs[1], 5); len(got) != 3 {
+		t.Errorf("similar(1) after update returned %d articles, want 3", len(got))
+	}
+}
+
+func TestTFIDFIndexReplace(t *testing.T) {
+	articles := []models.Article{
+		{ID: 1, Title: "RBI keeps repo rate unchanged"},
+		{ID: 2, Title: "Repo rate pause: what RBI policy means for home loans"},
+	}
+	build := func() *tfidfIndex {
+		idx := newTFIDFIndex()
+		for _, a := range articles {
+			idx.add(a.ID, articleTerms(a))
+		}
+		return idx
+	}
+
+	// Lookups during a rebuild see the old index until the new one is
+	// swapped in, never an empty one
+	idx := build()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			idx.replace(build())
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		terms, ok := idx.lookup(1)
+		if !ok {
+			t.Fatal("article 1 missing from the index during a rebuild")
+		}
+		if got := idx.similar(1, terms, 5); len(got) != 1 {
+			t.Fatalf("similar(1) during a rebuild returned %d articles, want 1", len(got))
+		}
+	}
+}
+
+func TestTFIDFIndexReplaysAddsDuringRebuild(t *testing.T) {
+	idx := newTFIDFIndex()
+	idx.add(1, articleTerms(models.Article{ID: 1, Title: "RBI keeps repo rate unchanged"}))
+
+	// An article stored while the rebuild scans is missing from the rebuilt
+	// index, and must not be lost when it is swapped in
+	idx.beginRebuild()
+	rebuilt := newTFIDFIndex()
+	rebuilt.add(1, articleTerms(models.Article{ID: 1, Title: "RBI keeps repo rate unchanged"}))
+	idx.add(2, articleTerms(models.Article{ID: 2, Title: "RBI repo rate pause explained"}))
+	idx.replace(rebuilt)
+
+	terms, ok := idx.lookup(2)
+	if !ok {
+		t.Fatal("article added during the rebuild was lost")
+	}
+	if got := idx.similar(2, terms, 5); len(got) != 1 || got[0].id != 1 {
+		t.Errorf("similar(2) after the rebuild = %+v, want article 1", got)
+	}
+	if idx.pending != nil {
+		t.Error("articles are still recorded after the rebuild")
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-news-aggregator/internal/database"
+)
+
+// defaultSummaryCacheDays is how long summaries of submitted text and of
+// pages that are not stored articles are kept unless SUMMARY_CACHE_DAYS
+// says otherwise
+const defaultSummaryCacheDays = 30
+
+// defaultRetentionPolicy keeps full content for 90 days and metadata for a year
+var defaultRetentionPolicy = database.RetentionPolicy{
+	Source:      "*",
+	ContentDays: 90,
+	ArchiveDays: 365,
+}
+
+// ParseRetentionPolicies parses a policy list of the form
+// "source:contentDays:archiveDays,..." where "*" matches any other source,
+// e.g. "*:90:365,Livemint:30:180".
+func ParseRetentionPolicies(spec string) ([]database.RetentionPolicy, error) {
+	var policies []database.RetentionPolicy
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid retention policy %q: expected source:contentDays:archiveDays", entry)
+		}
+
+		contentDays, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || contentDays < 0 {
+			return nil, fmt.Errorf("invalid content days in retention policy %q", entry)
+		}
+		archiveDays, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		if err != nil || archiveDays < 0 {
+			return nil, fmt.Errorf("invalid archive days in retention policy %q", entry)
+		}
+
+		policies = append(policies, database.RetentionPolicy{
+			Source:      strings.TrimSpace(parts[0]),
+			ContentDays: contentDays,
+			ArchiveDays: archiveDays,
+		})
+	}
+	return policies, nil
+}
+
+// RetentionPoliciesFromEnv reads RETENTION_POLICY, falling back to the default policy
+func RetentionPoliciesFromEnv() []database.RetentionPolicy {
+	spec := os.Getenv("RETENTION_POLICY")
+	if spec == "" {
+		return []database.RetentionPolicy{defaultRetentionPolicy}
+	}
+
+	policies, err := ParseRetentionPolicies(spec)
+	if err != nil {
+		log.Printf("Invalid RETENTION_POLICY, using default: %v", err)
+		return []database.RetentionPolicy{defaultRetentionPolicy}
+	}
+	return policies
+}
+
+// SummaryCacheDays is how many days summaries not tied to a stored article
+// are kept, from SUMMARY_CACHE_DAYS
+func SummaryCacheDays() int {
+	if days, err := strconv.Atoi(os.Getenv("SUMMARY_CACHE_DAYS")); err == nil && days > 0 {
+		return days
+	}
+	return defaultSummaryCacheDays
+}
+
+// RunRetention applies the configured retention policies and logs what was removed
+func RunRetention(archivePath string) error {
+	policies := RetentionPoliciesFromEnv()
+
+	log.Println("Starting retention pass...")
+	runs, err := database.ApplyRetention(policies, archivePath, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		log.Printf("Retention for %s: stripped content from %d articles, archived %d articles",
+			run.Source, run.ContentStripped, run.Archived)
+	}
+	log.Printf("Retention pass completed for %d sources", len(runs))
+
+	// Nothing else removes cached summaries of submitted text or of pages
+	// that are not stored articles
+	deleted, err := database.DeleteUnlinkedSummaries(time.Now().AddDate(0, 0, -SummaryCacheDays()))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d cached summaries older than %d days", deleted, SummaryCacheDays())
+	}
+
+	// Archived articles must stop showing up as related reading
+	return BuildRelatedIndex()
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+)
+
+// sentimentVersion is bumped whenever the lexicon or scoring changes, so
+// stored scores are recomputed on the next start
+const sentimentVersion = "2"
+
+// sentimentBackfillBatch is how many articles are scored per batch at startup
+const sentimentBackfillBatch = 500
+
+// MaxSentimentDays is the longest period daily sentiment is aggregated over
+const MaxSentimentDays = 365
+
+// Sentiment labels
+const (
+	SentimentPositive = "positive"
+	SentimentNegative = "negative"
+	SentimentNeutral  = "neutral"
+)
+
+const (
+	// sentimentTitleWeight counts headline words more, since they carry the
+	// story's tone
+	sentimentTitleWeight = 2
+	// sentimentSmoothing damps scores that rest on one or two words
+	sentimentSmoothing = 2
+	// sentimentThreshold is how far from zero a score must be to get a
+	// positive or negative label
+	sentimentThreshold = 0.2
+	// negationWindow is how many words back a negation flips a match
+	negationWindow = 3
+)
+
+// positiveWords and negativeWords follow the Loughran-McDonald financial
+// word lists, trimmed to the words that show up in market headlines and
+// extended with their inflections
+var positiveWords = wordSet(`
+	gain gains gained gainer gainers rally rallies rallied surge surges surged
+	jump jumps jumped soar soars soared rise rises rising rose climb climbs climbed
+	advance advances advanced beat beats outperform outperforms outperformed
+	upgrade upgrades upgraded strong stronger strongest strength robust record
+	growth grow grows grew improve improves improved improvement boost boosts boosted
+	bullish optimism optimistic positive recover recovers recovered recovery
+	rebound rebounds rebounded expand expands expanded expansion win wins won
+	bags bagged approval approved approves buyback upbeat tailwind tailwinds
+	momentum inflow inflows profitable profitability efficient attractive
+	favourable favorable success successful breakthrough milestone highest
+	strengthen strengthens strengthened upside outperformance
+`)
+
+var negativeWords = wordSet(`
+	fall falls fell falling drop drops dropped decline declines declined declining
+	slump slumps slumped plunge plunges plunged crash crashes crashed tumble tumbles
+	tumbled sink sinks sank slide slides slid loss losses lose loses lost weak weaker
+	weakness downgrade downgrades downgraded bearish pessimism pessimistic concern
+	concerns worried worries default defaults defaulted fraud probe penalty penalties
+	lawsuit litigation investigation slowdown recession selloff underperform
+	underperforms underperformed miss misses missed layoffs resign resigns resigned
+	resignation warning warns warned headwind headwinds pressure outflow outflows
+	downturn delay delays delayed halt halts halted suspend suspended suspension
+	ban banned complaint complaints dispute disputes shortfall erode erodes eroded
+	crisis bankrupt bankruptcy insolvency volatile volatility uncertainty
+	lowest slowdown adverse impairment writeoff downside negative scam violation
+`)
+
+// sentimentPhrases are multi-word expressions from Indian market coverage.
+// A matched phrase replaces the scores of its individual words. Block deals
+// and stake sales usually mean promoters or funds exiting, so they lean
+// slightly negative.
+var sentimentPhrases = phraseWeights(map[string]float64{
+	"upper circuit":           2,
+	"lower circuit":           -2,
+	"rating upgrade":          2,
+	"rating downgrade":        -2,
+	"target price raised":     1.5,
+	"raises target price":     1.5,
+	"target price cut":        -1.5,
+	"cuts target price":       -1.5,
+	"buy rating":              1,
+	"sell rating":             -1,
+	"52 week high":            1.5,
+	"52 week low":             -1.5,
+	"record high":             1.5,
+	"all time high":           1.5,
+	"profit booking":          -1,
+	"profit rises":            1.5,
+	"profit falls":            -1.5,
+	"beats estimates":         1.5,
+	"misses estimates":        -1.5,
+	"order win":               1.5,
+	"bags order":              1.5,
+	"promoter pledge":         -1,
+	"pledge released":         1,
+	"stake sale":              -0.5,
+	"block deal":              -0.5,
+	"fii selling":             -1.5,
+	"fii buying":              1.5,
+	"fpi outflows":            -1.5,
+	"fpi inflows":             1.5,
+	"margin pressure":         -1.5,
+	"bad loans":               -1.5,
+	"sell off":                -1.5,
+	"in the red":              -1,
+	"in the green":            1,
+	"under pressure":          -1.5,
+	"muted demand":            -1.5,
+	"weak demand":             -1.5,
+	"strong demand":           1.5,
+	"rating watch negative":   -2,
+	"going concern":           -2,
+	"show cause notice":       -1.5,
+	"sebi order":              -1,
+	"multibagger":             1,
+	"multibagger returns":     1.5,
+	"bonus issue":             1,
+	"stock split":             0.5,
+	"gst notice":              -1.5,
+	"tax demand":              -1.5,
+	"income tax raid":         -2,
+	"ed raid":                 -2,
+	"credit rating upgrade":   2,
+	"credit rating downgrade": -2,
+})
+
+// negators flip the polarity of a match that follows within negationWindow
+var negators = wordSet(`not no never without nor neither hardly cannot didn doesn isn wasn aren don fails failed`)
+
+// contrasts start a new clause, so a negation before one does not reach
+// the words after it, as in "did not fall despite weak results"
+var contrasts = wordSet(`but despite although though however whereas`)
+
+// clauseBreak stands in for punctuation that ends a clause in the output of
+// sentimentTokens
+const clauseBreak = "|"
+
+// clausePunctuation ends the reach of a negation
+const clausePunctuation = ".,;:!?"
+
+// sentimentTokens tokenizes like tokenize, but keeps clause-ending
+// punctuation as clauseBreak tokens so negation can stop there
+func sentimentTokens(text string) []string {
+	var tokens []string
+	start := -1
+	lower := strings.ToLower(text)
+	for i, r := range lower {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, lower[start:i])
+			start = -1
+		}
+		if strings.ContainsRune(clausePunctuation, r) && len(tokens) > 0 && tokens[len(tokens)-1] != clauseBreak {
+			tokens = append(tokens, clauseBreak)
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, lower[start:])
+	}
+	return tokens
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+type sentimentPhrase struct {
+	words  []string
+	weight float64
+}
+
+// phraseWeights indexes phrases by their first word, longest first
+func phraseWeights(phrases map[string]float64) map[string][]sentimentPhrase {
+	index := make(map[string][]sentimentPhrase)
+	for text, weight := range phrases {
+		words := tokenize(text)
+		index[words[0]] = append(index[words[0]], sentimentPhrase{words: words, weight: weight})
+	}
+	for _, list := range index {
+		sort.Slice(list, func(i, j int) bool { return len(list[i].words) > len(list[j].words) })
+	}
+	return index
+}
+
+// ScoreSentiment scores an article's title and body against the finance
+// lexicon. Headline words count double.
+func ScoreSentiment(title, body string) models.ArticleSentiment {
+	tp, tn := polarity(sentimentTokens(title))
+	bp, bn := polarity(sentimentTokens(body))
+	positive := sentimentTitleWeight*tp + bp
+	negative := sentimentTitleWeight*tn + bn
+
+	score := (positive - negative) / (positive + negative + sentimentSmoothing)
+	score = math.Round(score*1000) / 1000
+
+	label := SentimentNeutral
+	switch {
+	case score >= sentimentThreshold:
+		label = SentimentPositive
+	case score <= -sentimentThreshold:
+		label = SentimentNegative
+	}
+	return models.ArticleSentiment{Score: score, Label: label}
+}
+
+// polarity sums the positive and negative weight of the words, matching
+// phrases before single words and flipping matches that follow a negation
+func polarity(words []string) (positive, negative float64) {
+	for i := 0; i < len(words); {
+		weight, length := 0.0, 1
+		matched := false
+		for _, p := range sentimentPhrases[words[i]] {
+			if hasPhrase(words, i, p.words) {
+				weight, length, matched = p.weight, len(p.words), true
+				break
+			}
+		}
+		if !matched {
+			switch {
+			case positiveWords[words[i]]:
+				weight = 1
+			case negativeWords[words[i]]:
+				weight = -1
+			}
+		}
+
+		if weight != 0 && negated(words, i) {
+			weight = -weight
+		}
+		if weight > 0 {
+			positive += weight
+		} else {
+			negative -= weight
+		}
+		i += length
+	}
+	return positive, negative
+}
+
+func hasPhrase(words []string, i int, phrase []string) bool {
+	if i+len(phrase) > len(words) {
+		return false
+	}
+	for j, word := range phrase {
+		if words[i+j] != word {
+			return false
+		}
+	}
+	return true
+}
+
+// negated reports whether a negation appears shortly before words[i] in
+// the same clause
+func negated(words []string, i int) bool {
+	for j := i - 1; j >= 0 && j >= i-negationWindow; j-- {
+		if words[j] == clauseBreak || contrasts[words[j]] {
+			return false
+		}
+		if negators[words[j]] {
+			return true
+		}
+	}
+	return false
+}
+
+// ScoreArticleSentiment scores and stores an article's sentiment
+func ScoreArticleSentiment(article models.Article) error {
+	sentiment := ScoreSentiment(article.Title, article.Description+"\n"+article.Content)
+	return database.SaveArticleSentiment(article.ID, sentiment, sentimentVersion)
+}
+
+// BackfillSentiment scores every article stored before the current lexicon
+func BackfillSentiment() error {
+	var scored int
+	for {
+		articles, err := database.GetArticlesToScore(sentimentVersion, sentimentBackfillBatch)
+		if err != nil {
+			return err
+		}
+		if len(articles) == 0 {
+			break
+		}
+		for _, article := range articles {
+			if err := ScoreArticleSentiment(article); err != nil {
+				return err
+			}
+		}
+		scored += len(articles)
+	}
+	if scored > 0 {
+		log.Printf("Scored sentiment for %d stored articles", scored)
+	}
+	return nil
+}
+
+// sentimentSince is the start of the market day days-1 days ago, so a
+// period of one day covers today only
+func sentimentSince(days int) time.Time {
+	now := time.Now().In(MarketLocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, MarketLocation)
+	return today.AddDate(0, 0, 1-days)
+}
+
+// GetTickerSentiment returns a company's daily sentiment over the last days
+// market days, oldest first. Days without articles are left out.
+func GetTickerSentiment(symbol string, days int) ([]database.DailySentiment, error) {
+	return database.GetTickerSentiment(symbol, sentimentSince(days), MarketLocation)
+}
+
+// GetSourceSentiment returns each source's daily sentiment over the last
+// days market days
+func GetSourceSentiment(days int) ([]database.SourceSentiment, error) {
+	return database.GetSourceSentiment(sentimentSince(days), MarketLocation)
+}
@@ -0,0 +1,67 @@
+package services
+
+import "testing"
+
+func TestScoreSentiment(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		body  string
+		label string
+	}{
+		{"upper circuit", "Suzlon Energy shares hit upper circuit", "", SentimentPositive},
+		{"lower circuit", "Paytm stock locked in lower circuit", "", SentimentNegative},
+		{"rating downgrade", "Moody's rating downgrade for Vedanta", "", SentimentNegative},
+		{"rating upgrade", "Rating upgrade lifts Tata Motors", "", SentimentPositive},
+		{"block deal", "Block deal in Zomato: 2% equity changes hands", "", SentimentNegative},
+		{"word list", "Sensex falls 500 points as IT stocks slump", "", SentimentNegative},
+		{"negation", "Infosys shares did not fall after results", "", SentimentPositive},
+		{"neutral", "RBI policy meeting scheduled for Friday", "", SentimentNeutral},
+		{"title outweighs body", "HDFC Bank profit rises 12%", "Analysts see some pressure on margins.", SentimentPositive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScoreSentiment(tt.title, tt.body)
+			if got.Label != tt.label {
+				t.Errorf("ScoreSentiment(%q) = %+v, want label %s", tt.title, got, tt.label)
+			}
+			if got.Score < -1 || got.Score > 1 {
+				t.Errorf("ScoreSentiment(%q) score %v out of range", tt.title, got.Score)
+			}
+		})
+	}
+}
+
+func TestScoreSentimentPhraseReplacesWords(t *testing.T) {
+	// "negative" is also in the word list, but a matched phrase is counted
+	// once, not again word by word
+	got := ScoreSentiment("Rating watch negative on Adani Ports", "")
+	if got.Label != SentimentNegative {
+		t.Errorf("got %+v, want negative", got)
+	}
+	_, negative := polarity(tokenize("rating watch negative"))
+	if negative != 2 {
+		t.Errorf("rating watch negative polarity = %v, want 2", negative)
+	}
+}
+
+func TestNegationEndsAtClause(t *testing.T) {
+	// The negation flips "fall" but not the sentiment word after the clause
+	// ends, so one positive and one negative remain
+	for _, text := range []string{
+		"did not fall despite weak results",
+		"did not fall but weak results",
+		"did not fall; weak results",
+	} {
+		positive, negative := polarity(sentimentTokens(text))
+		if positive != 1 || negative != 1 {
+			t.Errorf("polarity(%q) = %v, %v, want 1, 1", text, positive, negative)
+		}
+	}
+
+	positive, negative := polarity(sentimentTokens("did not post weak results"))
+	if positive != 1 || negative != 0 {
+		t.Errorf("negation within a clause: polarity = %v, %v, want 1, 0", positive, negative)
+	}
+}
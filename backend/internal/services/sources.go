@@ -0,0 +1,52 @@
+package services
+
+import "strings"
+
+// knownSources lists the source names articles are stored under
+var knownSources = []string{
+	"Livemint",
+	"Economic Times",
+	"MoneyControl",
+	"Groww",
+	"Business Standard",
+	"India Today",
+	"Business Today",
+}
+
+// sourceAliases maps the short names analysts use to stored source names
+var sourceAliases = map[string]string{
+	"mint": "Livemint",
+	"lm":   "Livemint",
+	"et":   "Economic Times",
+	"mc":   "MoneyControl",
+	"bs":   "Business Standard",
+	"it":   "India Today",
+	"bt":   "Business Today",
+}
+
+// CanonicalSource resolves a source name or alias to the name articles are
+// stored under. Unknown names are returned unchanged.
+func CanonicalSource(name string) string {
+	name = strings.TrimSpace(name)
+	if canonical, ok := sourceAliases[strings.ToLower(name)]; ok {
+		return canonical
+	}
+	for _, source := range knownSources {
+		if strings.EqualFold(source, name) {
+			return source
+		}
+	}
+	return name
+}
+
+// sourceDomains are the sites articles are scraped from. Pages on them and
+// their subdomains may be fetched for summarizing.
+var sourceDomains = []string{
+	"livemint.com",
+	"economictimes.indiatimes.com",
+	"moneycontrol.com",
+	"groww.in",
+	"business-standard.com",
+	"indiatoday.in",
+	"businesstoday.in",
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"sort"
+
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/summarize"
+)
+
+const (
+	// storySimilarity is the TF-IDF cosine similarity above which two
+	// articles are taken to report the same story
+	storySimilarity = 0.3
+	// storySentences is the length of a story summary
+	storySentences = 3
+)
+
+// Story is a news event reported by one or more articles, with a summary
+// drawn from all of them
+type Story struct {
+	Title    string              `json:"title"`
+	Summary  string              `json:"summary"`
+	Sources  []string            `json:"sources"`
+	Articles []models.ArticleDTO `json:"articles"`
+}
+
+// GroupStories groups articles about the same event into stories and
+// summarizes each from all its articles, with repeated sentences across
+// sources removed. Stories covered by the most sources come first, then
+// those with the most articles.
+func GroupStories(articles []models.Article) []Story {
+	// Oldest first, so each story is titled by the article that broke it
+	sorted := append([]models.Article{}, articles...)
+	sort.SliceStable(sorted, func(i, j int) bool { return articleTime(sorted[i]).Before(articleTime(sorted[j])) })
+
+	terms := make([]map[string]float64, len(sorted))
+	for i, article := range sorted {
+		var ok bool
+		if terms[i], ok = relatedIndex.lookup(article.ID); !ok {
+			terms[i] = articleTerms(article)
+		}
+	}
+	vectors := relatedIndex.unitVectors(terms)
+
+	// An article joins the story it is most similar to on average, or
+	// starts a new one. Averaging keeps a chain of loosely similar articles,
+	// such as a run of brokerage calls, from merging into one story. The
+	// average cosine with a story's articles is the dot product with the
+	// sum of their vectors over their count, so only the sum is kept.
+	var groups [][]int
+	var sums []map[string]float64
+	for i, vector := range vectors {
+		best, bestScore := -1, storySimilarity
+		for g, group := range groups {
+			if score := dot(vector, sums[g]) / float64(len(group)); score >= bestScore {
+				best, bestScore = g, score
+			}
+		}
+		if best < 0 {
+			best = len(groups)
+			groups = append(groups, nil)
+			sums = append(sums, make(map[string]float64, len(vector)))
+		}
+		groups[best] = append(groups[best], i)
+		for term, w := range vector {
+			sums[best][term] += w
+		}
+	}
+
+	stories := make([]Story, 0, len(groups))
+	latest := make(map[int]int64, len(groups)) // story -> newest article time
+	for _, group := range groups {
+		story := Story{Title: sorted[group[0]].Title, Articles: []models.ArticleDTO{}}
+		seen := make(map[string]bool)
+		texts := make([]string, 0, len(group))
+		for _, i := range group {
+			article := sorted[i]
+			story.Articles = append(story.Articles, models.NewArticleDTO(article))
+			if !seen[article.Source.Name] {
+				seen[article.Source.Name] = true
+				story.Sources = append(story.Sources, article.Source.Name)
+			}
+			texts = append(texts, articleText(article))
+			latest[len(stories)] = articleTime(article).Unix()
+		}
+		if result, err := summarize.SummarizeDocuments(texts, summarize.Options{MaxSentences: storySentences}); err == nil {
+			story.Summary = result.Text()
+		}
+		stories = append(stories, story)
+	}
+
+	order := make([]int, len(stories))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := stories[order[a]], stories[order[b]]
+		if len(x.Sources) != len(y.Sources) {
+			return len(x.Sources) > len(y.Sources)
+		}
+		if len(x.Articles) != len(y.Articles) {
+			return len(x.Articles) > len(y.Articles)
+		}
+		return latest[order[a]] > latest[order[b]]
+	})
+	ranked := make([]Story, len(stories))
+	for i, j := range order {
+		ranked[i] = stories[j]
+	}
+	return ranked
+}
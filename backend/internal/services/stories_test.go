@@ -0,0 +1,85 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/models"
+)
+
+// storyArticles reports two events, one by three sources and one by two,
+// and a third event by a single source
+func storyArticles(day time.Time) []models.Article {
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+	return []models.Article{
+		{ID: 1, Source: models.Source{Name: "Economic Times"}, PublishedAt: at(11),
+			Title:       "RBI keeps repo rate unchanged at 6.5%",
+			Description: "The Reserve Bank of India kept the repo rate unchanged at 6.5% and retained its inflation forecast."},
+		{ID: 2, Source: models.Source{Name: "Livemint"}, PublishedAt: at(10),
+			Title:       "RBI policy: repo rate unchanged at 6.5%, inflation forecast retained",
+			Description: "The Reserve Bank of India left the repo rate unchanged at 6.5%. Governor said inflation is easing."},
+		{ID: 3, Source: models.Source{Name: "Groww"}, PublishedAt: at(9),
+			Title:       "Tata Motors shares jump on strong JLR sales",
+			Description: "Tata Motors shares rose 4% after Jaguar Land Rover reported strong quarterly sales."},
+		{ID: 4, Source: models.Source{Name: "Business Standard"}, PublishedAt: at(12),
+			Title:       "Repo rate unchanged: RBI holds at 6.5% as inflation eases",
+			Description: "The Reserve Bank of India held the repo rate unchanged at 6.5% with inflation easing."},
+		{ID: 5, Source: models.Source{Name: "Livemint"}, PublishedAt: at(13),
+			Title:       "JLR sales lift Tata Motors shares",
+			Description: "Strong Jaguar Land Rover sales sent Tata Motors shares up 4% in the quarter."},
+		{ID: 6, Source: models.Source{Name: "MoneyControl"}, PublishedAt: at(14),
+			Title:       "Gold prices hit record high",
+			Description: "Gold futures climbed to a record as the dollar weakened."},
+	}
+}
+
+func TestGroupStories(t *testing.T) {
+	day := time.Date(2026, 10, 9, 0, 0, 0, 0, MarketLocation)
+	stories := GroupStories(storyArticles(day))
+
+	var got [][]int64
+	for _, story := range stories {
+		var ids []int64
+		for _, article := range story.Articles {
+			ids = append(ids, article.ID)
+		}
+		got = append(got, ids)
+	}
+	// Most sources first; each story's articles oldest first
+	want := [][]int64{{2, 1, 4}, {3, 5}, {6}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GroupStories() grouped %v, want %v", got, want)
+	}
+
+	rbi := stories[0]
+	if rbi.Title != "RBI policy: repo rate unchanged at 6.5%, inflation forecast retained" {
+		t.Errorf("story title = %q, want the title of the article that broke it", rbi.Title)
+	}
+	if want := []string{"Livemint", "Economic Times", "Business Standard"}; !reflect.DeepEqual(rbi.Sources, want) {
+		t.Errorf("story sources = %v, want %v", rbi.Sources, want)
+	}
+	if rbi.Summary == "" {
+		t.Errorf("story has no summary")
+	}
+
+	if got := GroupStories(nil); len(got) != 0 {
+		t.Errorf("GroupStories(nil) = %v, want none", got)
+	}
+}
+
+func BenchmarkGroupStories(b *testing.B) {
+	day := time.Date(2026, 10, 9, 0, 0, 0, 0, MarketLocation)
+	var articles []models.Article
+	for len(articles) < maxDigestArticles {
+		for _, article := range storyArticles(day) {
+			article.ID = int64(len(articles) + 1)
+			articles = append(articles, article)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GroupStories(articles)
+	}
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"stock-news-aggregator/internal/database"
+)
+
+// Suggestion kinds
+const (
+	SuggestionCompany = "company"
+	SuggestionQuery   = "query"
+	SuggestionTitle   = "title"
+)
+
+// Suggestion is a single search-as-you-type completion
+type Suggestion struct {
+	Text  string  `json:"text"`
+	Kind  string  `json:"kind"`
+	Score float64 `json:"score"`
+}
+
+const (
+	// maxSuggestTitles is how many recent titles are indexed for completion
+	maxSuggestTitles = 2000
+	// maxTrackedQueries caps the popular query table; the stalest are evicted
+	maxTrackedQueries = 1000
+	// minTrackedQueryLen skips recording the fragments typed on the way to a query
+	minTrackedQueryLen = 3
+	// queryHalfLife is how quickly a query's popularity fades
+	queryHalfLife = 24 * time.Hour
+)
+
+// Base scores per kind, so a company name outranks a headline with the same match
+var suggestionWeights = map[string]float64{
+	SuggestionCompany: 3,
+	SuggestionQuery:   2,
+	SuggestionTitle:   1,
+}
+
+// suggestEntry is a completion candidate with the words it can be matched on
+type suggestEntry struct {
+	text  string
+	kind  string
+	boost float64 // kind-specific ranking signal in [0, 1]
+}
+
+// suggestWord points a lower-cased word at the entry containing it
+type suggestWord struct {
+	word     string
+	entry    int
+	position int // index of the word within the entry
+}
+
+type queryStat struct {
+	text     string
+	score    float64 // decayed hit count as of lastSeen
+	lastSeen time.Time
+}
+
+// suggestIndex serves completions from memory so lookups never touch the database
+type suggestIndex struct {
+	mu      sync.RWMutex
+	entries []suggestEntry
+	words   []suggestWord // sorted by word
+	queries map[string]*queryStat
+}
+
+var suggestions = &suggestIndex{queries: make(map[string]*queryStat)}
+
+// RefreshSuggestions rebuilds the title completions from the most recently
+// stored articles and the company completions from the symbol master
+func RefreshSuggestions() error {
+	titles, err := database.GetRecentTitles(maxSuggestTitles)
+	if err != nil {
+		return err
+	}
+	suggestions.rebuild(titles, companyNames())
+	return nil
+}
+
+// RecordSearchQuery counts a search towards the popular query completions
+func RecordSearchQuery(q string) {
+	suggestions.recordQuery(q, time.Now())
+}
+
+// Suggest returns up to limit ranked, de-duplicated completions for prefix
+func Suggest(prefix string, limit int) []Suggestion {
+	return suggestions.suggest(prefix, limit, time.Now())
+}
+
+// rebuild replaces the indexed titles and companies. Titles are expected
+// newest first.
+func (idx *suggestIndex) rebuild(titles, companies []string) {
+	var entries []suggestEntry
+	for _, company := range companies {
+		entries = append(entries, suggestEntry{text: company, kind: SuggestionCompany, boost: 1})
+	}
+	for i, title := range titles {
+		// Newer headlines rank higher
+		entries = append(entries, suggestEntry{
+			text:  title,
+			kind:  SuggestionTitle,
+			boost: 1 - float64(i)/float64(len(titles)),
+		})
+	}
+
+	var words []suggestWord
+	for i, entry := range entries {
+		for position, word := range suggestTokens(entry.text) {
+			words = append(words, suggestWord{word: word, entry: i, position: position})
+		}
+	}
+	sort.Slice(words, func(i, j int) bool { return words[i].word < words[j].word })
+
+	idx.mu.Lock()
+	idx.entries = entries
+	idx.words = words
+	idx.mu.Unlock()
+}
+
+func (idx *suggestIndex) recordQuery(q string, now time.Time) {
+	q = strings.Join(strings.Fields(q), " ")
+	if len(q) < minTrackedQueryLen {
+		return
+	}
+	key := strings.ToLower(q)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if stat, ok := idx.queries[key]; ok {
+		stat.score = decay(stat.score, now.Sub(stat.lastSeen)) + 1
+		stat.lastSeen = now
+		return
+	}
+
+	if len(idx.queries) >= maxTrackedQueries {
+		var stalest string
+		for k, stat := range idx.queries {
+			if stalest == "" || stat.lastSeen.Before(idx.queries[stalest].lastSeen) {
+				stalest = k
+			}
+		}
+		delete(idx.queries, stalest)
+	}
+	idx.queries[key] = &queryStat{text: q, score: 1, lastSeen: now}
+}
+
+func (idx *suggestIndex) suggest(prefix string, limit int, now time.Time) []Suggestion {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || limit <= 0 {
+		return []Suggestion{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	best := make(map[string]Suggestion)
+	add := func(text, kind string, score float64) {
+		key := strings.ToLower(text)
+		if current, ok := best[key]; !ok || score > current.Score {
+			best[key] = Suggestion{Text: text, Kind: kind, Score: score}
+		}
+	}
+
+	// Titles and companies where a word starts with the last word typed and
+	// any earlier words appear in full. Matching the first word, or the whole
+	// text, counts for more.
+	typed := suggestTokens(prefix)
+	if len(typed) > 0 {
+		last, earlier := typed[len(typed)-1], typed[:len(typed)-1]
+		start := sort.Search(len(idx.words), func(i int) bool { return idx.words[i].word >= last })
+		for i := start; i < len(idx.words) && strings.HasPrefix(idx.words[i].word, last); i++ {
+			w := idx.words[i]
+			entry := idx.entries[w.entry]
+			if len(earlier) > 0 && !containsWords(suggestTokens(entry.text), earlier) {
+				continue
+			}
+
+			score := suggestionWeights[entry.kind] + entry.boost
+			if strings.HasPrefix(strings.ToLower(entry.text), prefix) {
+				score += 1
+			} else if w.position > len(earlier) {
+				score -= 0.5
+			}
+			add(entry.text, entry.kind, score)
+		}
+	}
+
+	// Popular queries starting with the prefix, ranked by decayed frequency
+	for key, stat := range idx.queries {
+		if !strings.HasPrefix(key, prefix) || key == prefix {
+			continue
+		}
+		popularity := decay(stat.score, now.Sub(stat.lastSeen))
+		add(stat.text, SuggestionQuery, suggestionWeights[SuggestionQuery]+1+popularity/(1+popularity))
+	}
+
+	results := make([]Suggestion, 0, len(best))
+	for _, s := range best {
+		results = append(results, s)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Text < results[j].Text
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// containsWords reports whether every one of want appears in words
+func containsWords(words, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, word := range words {
+			if word == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// decay fades a score by how long ago it was last updated
+func decay(score float64, age time.Duration) float64 {
+	return score * math.Pow(0.5, age.Hours()/queryHalfLife.Hours())
+}
+
+// suggestTokens lower-cases text and splits it into words for prefix matching
+func suggestTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&' && r != '\''
+	})
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestSuggestIndex(titles, companies []string) *suggestIndex {
+	idx := &suggestIndex{queries: make(map[string]*queryStat)}
+	idx.rebuild(titles, companies)
+	return idx
+}
+
+func suggestionTexts(results []Suggestion) []string {
+	texts := make([]string, len(results))
+	for i, s := range results {
+		texts[i] = s.Text
+	}
+	return texts
+}
+
+func TestSuggestRanking(t *testing.T) {
+	idx := newTestSuggestIndex(
+		[]string{
+			"Tata Motors shares jump after JLR sales",
+			"Why Tata Steel fell today",
+			"Tata Motors demerger gets board nod",
+		},
+		[]string{"Tata Motors", "Tata Steel", "Reliance Industries"},
+	)
+
+	// Companies outrank headlines, and newer headlines outrank older ones
+	got := suggestionTexts(idx.suggest("tata mo", 10, time.Now()))
+	want := []string{
+		"Tata Motors",
+		"Tata Motors shares jump after JLR sales",
+		"Tata Motors demerger gets board nod",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggest(tata mo) = %q, want %q", got, want)
+	}
+
+	// Text starting with the prefix outranks a later word match
+	got = suggestionTexts(idx.suggest("steel", 10, time.Now()))
+	want = []string{"Tata Steel", "Why Tata Steel fell today"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggest(steel) = %q, want %q", got, want)
+	}
+
+	if got := idx.suggest("tata", 2, time.Now()); len(got) != 2 {
+		t.Errorf("suggest with limit 2 returned %d results", len(got))
+	}
+}
+
+func TestSuggestPrefixes(t *testing.T) {
+	idx := newTestSuggestIndex(
+		[]string{"Reliance Jio tariff hike", "HDFC Bank Q2 results", "M&M launches new SUV"},
+		[]string{"Reliance Industries"},
+	)
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		// A partial last word matches the start of any word
+		{"reli", []string{"Reliance Industries", "Reliance Jio tariff hike"}},
+		{"jio", []string{"Reliance Jio tariff hike"}},
+		{"  HDFC  ba", []string{"HDFC Bank Q2 results"}},
+		// Earlier words must appear in full
+		{"reliance ind", []string{"Reliance Industries"}},
+		{"rel ind", []string{}},
+		{"m&m", []string{"M&M launches new SUV"}},
+		{"infosys", []string{}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		got := suggestionTexts(idx.suggest(tt.prefix, 10, time.Now()))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("suggest(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestDeduplicates(t *testing.T) {
+	now := time.Now()
+	idx := newTestSuggestIndex(
+		[]string{"Infosys", "infosys buyback"},
+		[]string{"Infosys"},
+	)
+	idx.recordQuery("Infosys Buyback", now)
+	idx.recordQuery("infosys  buyback", now)
+
+	// Each text appears once, as its best-scoring kind
+	got := idx.suggest("infosys", 10, now)
+	want := []Suggestion{
+		{Text: "Infosys", Kind: SuggestionCompany},
+		{Text: "Infosys Buyback", Kind: SuggestionQuery},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("suggest(infosys) = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Text != want[i].Text || got[i].Kind != want[i].Kind {
+			t.Errorf("suggestion %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSuggestPopularQueries(t *testing.T) {
+	now := time.Now()
+	idx := newTestSuggestIndex(nil, nil)
+
+	idx.recordQuery("ad", now) // too short to track
+	idx.recordQuery("adani ports", now.Add(-72*time.Hour))
+	for i := 0; i < 3; i++ {
+		idx.recordQuery("adani green", now)
+	}
+	idx.recordQuery("adani power", now)
+
+	// More frequent and more recent queries rank first
+	got := suggestionTexts(idx.suggest("ad", 10, now))
+	want := []string{"adani green", "adani power", "adani ports"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggest(ad) = %q, want %q", got, want)
+	}
+
+	// The query already typed is not suggested back
+	got = suggestionTexts(idx.suggest("adani green", 10, now))
+	if len(got) != 0 {
+		t.Errorf("suggest(adani green) = %q, want none", got)
+	}
+}
+
+func TestRecordQueryEvictsStalest(t *testing.T) {
+	now := time.Now()
+	idx := newTestSuggestIndex(nil, nil)
+	for i := 0; i < maxTrackedQueries; i++ {
+		idx.recordQuery(fmt.Sprintf("query %d", i), now.Add(time.Duration(i)*time.Second))
+	}
+	idx.recordQuery("one more", now.Add(time.Hour))
+
+	if len(idx.queries) != maxTrackedQueries {
+		t.Errorf("tracking %d queries, want %d", len(idx.queries), maxTrackedQueries)
+	}
+	if _, ok := idx.queries["query 0"]; ok {
+		t.Errorf("stalest query was not evicted")
+	}
+}
+
+func BenchmarkSuggest(b *testing.B) {
+	// A full index: the most titles and queries kept, and a symbol master's
+	// worth of companies
+	titles := make([]string, maxSuggestTitles)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("Tata Motors stock %d rallies as market sentiment improves on results day %d", i, i%31)
+	}
+	companies := make([]string, 2500)
+	for i := range companies {
+		companies[i] = fmt.Sprintf("Company %d Industries Limited", i)
+	}
+	idx := newTestSuggestIndex(titles, companies)
+	now := time.Now()
+	for i := 0; i < maxTrackedQueries; i++ {
+		idx.recordQuery(fmt.Sprintf("tata query %d", i), now)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.suggest("tata mo", 8, now)
+	}
+}
@@ -2,11 +2,21 @@ package services
 
 import (
 	"fmt"
-	"net/http"
-	"strings"
-	"unicode"
+	"log"
+	"strconv"
 
-	"github.com/PuerkitoBio/goquery"
+	"stock-news-aggregator/internal/summarize"
+)
+
+// summarizerVersion is bumped whenever summarization changes, so cached
+// summaries are regenerated
+const summarizerVersion = "6"
+
+// getAlgorithm and registerAlgorithm reach the summarize package's
+// algorithms; tests swap them to keep their own summarizers to themselves
+var (
+	getAlgorithm      = summarize.Get
+	registerAlgorithm = summarize.Register
 )
 
 // TextSummarizer provides text summarization functionality
@@ -14,7 +24,8 @@ type TextSummarizer struct {
 	maxSentences int
 }
 
-// NewTextSummarizer creates a new instance of TextSummarizer
+// NewTextSummarizer creates a new instance of TextSummarizer. maxSentences
+// is the summary length when a request does not choose one.
 func NewTextSummarizer(maxSentences int) *TextSummarizer {
 	if maxSentences <= 0 {
 		maxSentences = 5 // default value
@@ -22,130 +33,142 @@ func NewTextSummarizer(maxSentences int) *TextSummarizer {
 	return &TextSummarizer{maxSentences: maxSentences}
 }
 
-// SummarizeURL fetches content from a URL and summarizes it
-func (ts *TextSummarizer) SummarizeURL(url string) (string, error) {
-	// Fetch the webpage content
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Parse the HTML content
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML: %v", err)
-	}
+// SummaryOptions choose how a summary is made. Zero values take the
+// defaults: the default algorithm, the summarizer's sentence count and the
+// paragraph format. MaxSentences takes precedence over Ratio, and MaxWords
+// caps either.
+type SummaryOptions struct {
+	Algorithm    string
+	MaxSentences int
+	Ratio        float64 // fraction of the text's sentences, over 0 and up to 1
+	MaxWords     int
+	Format       string // paragraph, bullets or tldr
+}
 
-	// Extract main article text
-	var articleText strings.Builder
-	doc.Find("p").Each(func(i int, s *goquery.Selection) {
-		articleText.WriteString(s.Text())
-		articleText.WriteString(" ")
-	})
+// summaryPlan is a request's SummaryOptions resolved against the
+// summarizer's defaults
+type summaryPlan struct {
+	algorithm summarize.Summarizer
+	fallback  summarize.Summarizer // used when algorithm fails, or nil
+	length    summarize.Options
+	format    string
+}
 
-	// Summarize the extracted text
-	return ts.Summarize(articleText.String())
+// ValidateSummaryOptions reports whether options name a known algorithm and
+// format and sensible lengths
+func ValidateSummaryOptions(opts SummaryOptions) error {
+	_, err := planSummary(opts, 1)
+	return err
 }
 
-// Summarize generates a summary of the given text
-func (ts *TextSummarizer) Summarize(text string) (string, error) {
-	if text == "" {
-		return "", fmt.Errorf("empty text provided")
+func planSummary(opts SummaryOptions, defaultSentences int) (*summaryPlan, error) {
+	algorithm, err := getAlgorithm(opts.Algorithm)
+	if err != nil {
+		return nil, err
 	}
-
-	// Split text into sentences
-	sentences := splitIntoSentences(text)
-	if len(sentences) == 0 {
-		return "", fmt.Errorf("no sentences found in text")
+	format, err := summarize.ParseFormat(opts.Format)
+	if err != nil {
+		return nil, err
 	}
-
-	// If text is already short enough, return as is
-	if len(sentences) <= ts.maxSentences {
-		return strings.Join(sentences, " "), nil
+	if opts.MaxSentences < 0 {
+		return nil, fmt.Errorf("maxSentences must not be negative")
 	}
-
-	// Simple extractive summarization:
-	// 1. Keep the first sentence (usually contains important context)
-	// 2. Score remaining sentences based on word importance
-	// 3. Select top N-1 sentences
-	
-	summary := []string{sentences[0]}
-	
-	// Score and select remaining sentences
-	type scoredSentence struct {
-		sentence string
-		score    float64
+	if opts.MaxWords < 0 {
+		return nil, fmt.Errorf("maxWords must not be negative")
 	}
-	
-	scored := make([]scoredSentence, len(sentences)-1)
-	for i, sent := range sentences[1:] {
-		scored[i] = scoredSentence{
-			sentence: sent,
-			score:    scoreSentence(sent, text),
-		}
+	if opts.Ratio < 0 || opts.Ratio > 1 {
+		return nil, fmt.Errorf("ratio must be between 0 and 1")
 	}
 
-	// Sort sentences by score (descending)
-	for i := 0; i < len(scored)-1; i++ {
-		for j := i + 1; j < len(scored); j++ {
-			if scored[j].score > scored[i].score {
-				scored[i], scored[j] = scored[j], scored[i]
-			}
-		}
+	length := summarize.Options{MaxSentences: opts.MaxSentences, Ratio: opts.Ratio, MaxWords: opts.MaxWords}
+	if length.MaxSentences == 0 && length.Ratio == 0 {
+		length.MaxSentences = defaultSentences
 	}
-
-	// Add top N-1 sentences to summary
-	for i := 0; i < ts.maxSentences-1 && i < len(scored); i++ {
-		summary = append(summary, scored[i].sentence)
+	if length.MaxSentences > 0 {
+		length.Ratio = 0
+	}
+	plan := &summaryPlan{
+		algorithm: algorithm,
+		length:    summarize.FormatOptions(format, length),
+		format:    format,
 	}
+	if algorithm.Name() == llmAlgorithm {
+		plan.fallback, _ = getAlgorithm(summarize.DefaultAlgorithm)
+	}
+	return plan, nil
+}
 
-	return strings.Join(summary, " "), nil
+func (ts *TextSummarizer) plan(opts SummaryOptions) (*summaryPlan, error) {
+	return planSummary(opts, ts.maxSentences)
 }
 
-// Helper function to split text into sentences
-func splitIntoSentences(text string) []string {
-	// Basic sentence splitting - can be improved
-	text = strings.TrimSpace(text)
-	sentences := strings.FieldsFunc(text, func(r rune) bool {
-		return r == '.' || r == '!' || r == '?'
-	})
-
-	// Clean up sentences
-	var result []string
-	for _, s := range sentences {
-		s = strings.TrimSpace(s)
-		if s != "" {
-			result = append(result, s+".")
-		}
-	}
-	return result
+// params identifies the settings summaries depend on, for the summary cache
+func (p *summaryPlan) params() string {
+	params := map[string]string{
+		"algorithm": p.algorithm.Name(),
+		"sentences": strconv.Itoa(p.length.MaxSentences),
+		"ratio":     strconv.FormatFloat(p.length.Ratio, 'g', -1, 64),
+		"words":     strconv.Itoa(p.length.MaxWords),
+		"format":    p.format,
+	}
+	if versioned, ok := p.algorithm.(summarize.Versioned); ok {
+		params["version"] = versioned.Version()
+	}
+	return encodeParams(params)
 }
 
-// Helper function to score a sentence based on word importance
-func scoreSentence(sentence, fullText string) float64 {
-	words := strings.FieldsFunc(sentence, unicode.IsSpace)
-	if len(words) == 0 {
-		return 0
+// summarize summarizes text as planned, falling back to the extractive
+// algorithm if the planned one fails and has a fallback
+func (p *summaryPlan) summarize(text string) (*Summary, error) {
+	algorithm := p.algorithm
+	result, err := algorithm.Summarize(text, p.length)
+	if err != nil && p.fallback != nil {
+		log.Printf("%s summarizer failed, falling back to %s: %v", algorithm.Name(), p.fallback.Name(), err)
+		algorithm = p.fallback
+		result, err = algorithm.Summarize(text, p.length)
 	}
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{
+		Text:      summarize.Format(result.Sentences, p.format, p.length.MaxWords),
+		Format:    p.format,
+		Algorithm: algorithm.Name(),
+		Indices:   result.Indices,
+		Fallback:  algorithm != p.algorithm,
+	}
+	// Written summaries have no source sentences to point at
+	if len(result.Indices) > 0 {
+		summary.Sentences = result.Sentences
+	}
+	return summary, nil
+}
 
-	// Simple scoring based on word frequency in full text
-	wordFreq := make(map[string]int)
-	for _, word := range strings.FieldsFunc(fullText, unicode.IsSpace) {
-		word = strings.ToLower(strings.Trim(word, ".,!?\"'()[]{}"))
-		if word != "" {
-			wordFreq[word]++
-		}
+// SummarizeURL fetches content from a URL and summarizes it with the
+// default options
+func (ts *TextSummarizer) SummarizeURL(url string) (string, error) {
+	text, err := fetchArticleText(url)
+	if err != nil {
+		return "", err
 	}
+	return ts.Summarize(text)
+}
 
-	// Calculate score based on average word importance
-	var score float64
-	for _, word := range words {
-		word = strings.ToLower(strings.Trim(word, ".,!?\"'()[]{}"))
-		if word != "" {
-			score += float64(wordFreq[word])
-		}
+// Summarize generates a summary of the given text with the default options
+func (ts *TextSummarizer) Summarize(text string) (string, error) {
+	summary, err := ts.SummarizeText(text, SummaryOptions{})
+	if err != nil {
+		return "", err
 	}
+	return summary.Text, nil
+}
 
-	return score / float64(len(words))
-} 
\ No newline at end of file
+// SummarizeText generates a summary of the given text with the given
+// options
+func (ts *TextSummarizer) SummarizeText(text string, opts SummaryOptions) (*Summary, error) {
+	plan, err := ts.plan(opts)
+	if err != nil {
+		return nil, err
+	}
+	return plan.summarize(text)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stock-news-aggregator/internal/summarize"
+)
+
+const llmArticle = "HDFC Bank reported a 12% rise in quarterly net profit to ₹16,000 crore. " +
+	"Net interest income grew 10% on loan growth. " +
+	"Asset quality improved, with gross NPAs falling to 1.2%."
+
+// llmStub serves chat completions, failing when ok is false
+func llmStub(t *testing.T, ok bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ok {
+			http.Error(w, `{"error": {"message": "model unavailable"}}`, http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "HDFC Bank's profit rose 12%."}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// useTestAlgorithms keeps summarizers registered for the rest of the test
+// apart from the package-wide algorithms, so later tests do not find them
+// pointing at a closed stub
+func useTestAlgorithms(t *testing.T) {
+	t.Helper()
+	get, register := getAlgorithm, registerAlgorithm
+	registered := make(map[string]summarize.Summarizer)
+	getAlgorithm = func(name string) (summarize.Summarizer, error) {
+		if s, ok := registered[strings.ToLower(name)]; ok {
+			return s, nil
+		}
+		return get(name)
+	}
+	registerAlgorithm = func(s summarize.Summarizer) {
+		registered[strings.ToLower(s.Name())] = s
+	}
+	t.Cleanup(func() { getAlgorithm, registerAlgorithm = get, register })
+}
+
+func TestLLMSummarizer(t *testing.T) {
+	useTestAlgorithms(t)
+
+	ts := NewTextSummarizer(2)
+	for _, tt := range []struct {
+		ok            bool
+		wantAlgorithm string
+	}{
+		{true, "llm"},
+		{false, "frequency"},
+	} {
+		t.Setenv("LLM_BASE_URL", llmStub(t, tt.ok).URL)
+		t.Setenv("LLM_TIMEOUT", "2s")
+		if err := LoadLLMSummarizer(); err != nil {
+			t.Fatal(err)
+		}
+
+		summary, err := ts.SummarizeText(llmArticle, SummaryOptions{Algorithm: "llm"})
+		if err != nil {
+			t.Fatalf("ok=%v: %v", tt.ok, err)
+		}
+		if summary.Algorithm != tt.wantAlgorithm || summary.Fallback == tt.ok {
+			t.Errorf("ok=%v: made by %s (fallback %v), want %s", tt.ok, summary.Algorithm, summary.Fallback, tt.wantAlgorithm)
+		}
+		if tt.ok && summary.Text != "HDFC Bank's profit rose 12%." {
+			t.Errorf("summary = %q", summary.Text)
+		}
+		if !tt.ok && len(summary.Indices) != 2 {
+			t.Errorf("fallback summary has indices %v, want 2", summary.Indices)
+		}
+	}
+}
+
+func TestLLMConfig(t *testing.T) {
+	t.Setenv("LLM_TIMEOUT", "soon")
+	if _, err := llmConfig(); err == nil {
+		t.Error("llmConfig accepted an invalid timeout")
+	}
+	t.Setenv("LLM_TIMEOUT", "")
+	t.Setenv("LLM_CONTEXT_TOKENS", "-5")
+	if _, err := llmConfig(); err == nil {
+		t.Error("llmConfig accepted a negative context size")
+	}
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	neturl "net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"stock-news-aggregator/internal/database"
+)
+
+// trackingParams are query parameters that do not change the page a URL
+// points to
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "ref": true, "ref_src": true, "from": true,
+	"source": true, "amp": true, "mc_cid": true, "mc_eid": true,
+}
+
+// Summary is a generated or cached summary
+type Summary struct {
+	Text      string
+	Format    string
+	Algorithm string // the algorithm that made it
+	Indices   []int  // positions of the summary's sentences in the source text
+	// Sentences are the source text's sentences at Indices, as they appear
+	// there, so they can be found and highlighted
+	Sentences []string
+	Cached    bool
+	Fallback  bool // made by the fallback algorithm because the requested one failed
+}
+
+// summaryCacheVersion identifies the summaries the current configuration
+// produces. Setting SUMMARY_CACHE_VERSION to a new value makes every cached
+// summary stale, so they are regenerated after the algorithm changes.
+func summaryCacheVersion() string {
+	version := summarizerVersion
+	if salt := os.Getenv("SUMMARY_CACHE_VERSION"); salt != "" {
+		version += ":" + salt
+	}
+	return version
+}
+
+// PruneSummaryCache deletes summaries cached by other summarizer versions
+func PruneSummaryCache() error {
+	deleted, err := database.DeleteStaleSummaries(summaryCacheVersion())
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d stale cached summaries", deleted)
+	}
+	return nil
+}
+
+// CanonicalURL normalizes a URL for use as a cache key: the scheme and host
+// are lower-cased and the fragment, tracking parameters and trailing slash
+// are dropped. Unparseable URLs are returned trimmed.
+func CanonicalURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := neturl.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	if len(u.Path) > 1 {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	}
+
+	query := u.Query()
+	for name := range query {
+		if strings.HasPrefix(strings.ToLower(name), "utm_") || trackingParams[strings.ToLower(name)] {
+			query.Del(name)
+		}
+	}
+	u.RawQuery = query.Encode() // sorted by key
+	return u.String()
+}
+
+// summaryKey keys a page's summary by its stored article when there is one,
+// or else by its canonical URL
+func summaryKey(rawURL, params string) database.SummaryKey {
+	canonical := CanonicalURL(rawURL)
+	key := database.SummaryKey{
+		Subject: canonical,
+		URL:     canonical,
+		Version: summaryCacheVersion(),
+		Params:  params,
+	}
+	if id, err := database.GetArticleIDByURL(rawURL, canonical); err == nil {
+		key = database.ArticleSummaryKey(id, key.Version, params)
+		key.URL = canonical
+	}
+	return key
+}
+
+// SummarizeURLCached summarizes a page with the given options, reusing the
+// stored summary if the page was already summarized with the same version
+// and parameters
+func SummarizeURLCached(ts *TextSummarizer, opts SummaryOptions, rawURL string) (*Summary, error) {
+	plan, err := ts.plan(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	key := summaryKey(rawURL, plan.params())
+	return cachedSummary(key, plan, func() (*Summary, error) {
+		text, err := fetchArticleText(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		return plan.summarize(text)
+	})
+}
+
+// SummarizeTextCached summarizes text with the given options, reusing the
+// stored summary if the same text was already summarized with the same
+// version and parameters
+func SummarizeTextCached(ts *TextSummarizer, opts SummaryOptions, text string) (*Summary, error) {
+	plan, err := ts.plan(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(text))
+	key := database.SummaryKey{
+		Subject: "text:" + hex.EncodeToString(sum[:]),
+		Version: summaryCacheVersion(),
+		Params:  plan.params(),
+	}
+	return cachedSummary(key, plan, func() (*Summary, error) {
+		return plan.summarize(text)
+	})
+}
+
+// cachedSummary returns the summary stored under key, or generates and
+// stores it. Fallback summaries are not stored, so the requested algorithm
+// is tried again next time.
+func cachedSummary(key database.SummaryKey, plan *summaryPlan, generate func() (*Summary, error)) (*Summary, error) {
+	if cached, err := database.GetSummary(key); err == nil {
+		return &Summary{
+			Text:      cached.Summary,
+			Format:    plan.format,
+			Algorithm: plan.algorithm.Name(),
+			Indices:   cached.Indices,
+			Sentences: cached.Sentences,
+			Cached:    true,
+		}, nil
+	} else if err != database.ErrNotFound {
+		log.Printf("Error reading summary cache: %v", err)
+	}
+
+	summary, err := generate()
+	if err != nil {
+		return nil, err
+	}
+	if summary.Fallback {
+		return summary, nil
+	}
+	cached := database.CachedSummary{Summary: summary.Text, Indices: summary.Indices, Sentences: summary.Sentences}
+	if err := database.SaveSummary(key, cached); err != nil {
+		log.Printf("Error caching summary: %v", err)
+	}
+	return summary, nil
+}
+
+// encodeParams renders summarizer parameters in a stable order
+func encodeParams(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + params[k]
+	}
+	return strings.Join(parts, "&")
+}
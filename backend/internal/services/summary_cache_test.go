@@ -0,0 +1,20 @@
+package services
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://www.livemint.com/market/story-123.html", "https://www.livemint.com/market/story-123.html"},
+		{"HTTPS://WWW.LiveMint.com/market/story-123.html#comments", "https://www.livemint.com/market/story-123.html"},
+		{"https://example.com/news/a/?utm_source=x&utm_medium=y&id=4&fbclid=z", "https://example.com/news/a?id=4"},
+		{"https://example.com/?b=2&a=1", "https://example.com/?a=1&b=2"},
+		{"  not a url  ", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalURL(tt.in); got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"stock-news-aggregator/internal/database"
+)
+
+// openTestDB points the database package at a fresh database for one test
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := database.InitDB(filepath.Join(t.TempDir(), "news.db")); err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+	t.Cleanup(func() { database.GetDB().Close() })
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords are common English words that carry no topical meaning
+var stopwords = map[string]bool{
+	"a": true, "about": true, "above": true, "after": true, "again": true, "against": true,
+	"all": true, "also": true, "am": true, "an": true, "and": true, "any": true, "are": true,
+	"as": true, "at": true, "be": true, "because": true, "been": true, "before": true,
+	"being": true, "below": true, "between": true, "both": true, "but": true, "by": true,
+	"can": true, "could": true, "did": true, "do": true, "does": true, "doing": true,
+	"down": true, "during": true, "each": true, "few": true, "for": true, "from": true,
+	"further": true, "had": true, "has": true, "have": true, "having": true, "he": true,
+	"her": true, "here": true, "hers": true, "him": true, "his": true, "how": true,
+	"i": true, "if": true, "in": true, "into": true, "is": true, "it": true, "its": true,
+	"itself": true, "just": true, "may": true, "me": true, "might": true, "more": true,
+	"most": true, "much": true, "must": true, "my": true, "no": true, "nor": true, "not": true,
+	"now": true, "of": true, "off": true, "on": true, "once": true, "only": true, "or": true,
+	"other": true, "our": true, "ours": true, "out": true, "over": true, "own": true,
+	"said": true, "same": true, "says": true, "she": true, "should": true, "so": true,
+	"some": true, "such": true, "than": true, "that": true, "the": true, "their": true,
+	"theirs": true, "them": true, "then": true, "there": true, "these": true, "they": true,
+	"this": true, "those": true, "through": true, "to": true, "too": true, "under": true,
+	"until": true, "up": true, "upon": true, "very": true, "was": true, "we": true,
+	"were": true, "what": true, "when": true, "where": true, "which": true, "while": true,
+	"who": true, "whom": true, "why": true, "will": true, "with": true, "would": true,
+	"you": true, "your": true, "yours": true,
+}
+
+// tokenize lower-cases text and splits it into words, dropping punctuation
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// contentTerms tokenizes text and drops stopwords and single characters
+func contentTerms(text string) []string {
+	var terms []string
+	for _, word := range tokenize(text) {
+		if len(word) > 1 && !stopwords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
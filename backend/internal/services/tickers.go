@@ -0,0 +1,118 @@
+package services
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/tickers"
+)
+
+// linkerVersion is bumped whenever matching changes, so stored links are
+// rebuilt on the next start
+const linkerVersion = "1"
+
+// tickerBackfillBatch is how many articles are linked per batch at startup
+const tickerBackfillBatch = 500
+
+var (
+	linker *tickers.Linker
+	// tickerVersion identifies the linker and symbol master articles were
+	// linked with
+	tickerVersion string
+)
+
+// SymbolsFile returns the symbol master path from SYMBOLS_FILE, defaulting
+// to data/symbols.csv
+func SymbolsFile() string {
+	if path := os.Getenv("SYMBOLS_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join("data", "symbols.csv")
+}
+
+// LoadSymbolMaster reads the listed companies articles are linked to
+func LoadSymbolMaster(path string) error {
+	companies, fingerprint, err := tickers.LoadCompanies(path)
+	if err != nil {
+		return err
+	}
+	linker = tickers.NewLinker(companies)
+	tickerVersion = linkerVersion + ":" + fingerprint
+	log.Printf("Loaded %d companies from %s", len(companies), path)
+	return nil
+}
+
+// LinkArticleTickers finds and stores the companies an article mentions,
+// most confident first
+func LinkArticleTickers(article models.Article) ([]models.ArticleTicker, error) {
+	if linker == nil {
+		return nil, nil
+	}
+
+	var links []models.ArticleTicker
+	for _, link := range linker.Link(article.Title, article.Description+"\n"+article.Content) {
+		links = append(links, models.ArticleTicker{
+			Symbol:     link.Symbol,
+			Name:       link.Name,
+			Confidence: link.Confidence,
+			Mentions:   link.Mentions,
+		})
+	}
+	return links, database.SaveArticleTickers(article.ID, links, tickerVersion)
+}
+
+// BackfillArticleTickers links every article stored before the current
+// symbol master was loaded
+func BackfillArticleTickers() error {
+	if linker == nil {
+		return nil
+	}
+
+	var linked int
+	for {
+		articles, err := database.GetArticlesToLink(tickerVersion, tickerBackfillBatch)
+		if err != nil {
+			return err
+		}
+		if len(articles) == 0 {
+			break
+		}
+		for _, article := range articles {
+			if _, err := LinkArticleTickers(article); err != nil {
+				return err
+			}
+		}
+		linked += len(articles)
+	}
+	if linked > 0 {
+		log.Printf("Linked companies for %d stored articles", linked)
+	}
+	return nil
+}
+
+// ResolveTicker maps a symbol, company name or alias such as "RIL" to its
+// symbol. Unknown values are upper-cased and used as given.
+func ResolveTicker(value string) string {
+	if linker != nil {
+		if symbol, ok := linker.Resolve(value); ok {
+			return symbol
+		}
+	}
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
+// companyNames lists the names of the known companies for search suggestions
+func companyNames() []string {
+	if linker == nil {
+		return nil
+	}
+	var names []string
+	for _, company := range linker.Companies() {
+		names = append(names, company.ShortName())
+	}
+	return names
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/search"
+	"stock-news-aggregator/internal/tickers"
+)
+
+// TimelineRanges are the periods a company timeline can cover, as Yahoo
+// chart ranges and how far back they reach
+var TimelineRanges = map[string]func(time.Time) time.Time{
+	"1mo": func(t time.Time) time.Time { return t.AddDate(0, -1, 0) },
+	"3mo": func(t time.Time) time.Time { return t.AddDate(0, -3, 0) },
+	"6mo": func(t time.Time) time.Time { return t.AddDate(0, -6, 0) },
+	"1y":  func(t time.Time) time.Time { return t.AddDate(-1, 0, 0) },
+}
+
+// maxTimelineArticles caps how many of a company's articles a timeline loads
+const maxTimelineArticles = 500
+
+// TimelineDay is one calendar day of a company's price and news
+type TimelineDay struct {
+	Date     string              `json:"date"`
+	Price    *PricePoint         `json:"price,omitempty"`
+	Articles []models.ArticleDTO `json:"articles"`
+}
+
+// Timeline is a company's news laid against its daily prices
+type Timeline struct {
+	Symbol     string        `json:"symbol"`
+	Name       string        `json:"name"`
+	Range      string        `json:"range"`
+	Days       []TimelineDay `json:"days"`
+	PriceError string        `json:"priceError,omitempty"`
+}
+
+// LookupCompany resolves a symbol, name or alias such as "RIL" to a company
+// in the symbol master. Without a symbol master any value is accepted as a
+// symbol.
+func LookupCompany(value string) (tickers.Company, bool) {
+	symbol := ResolveTicker(value)
+	if linker == nil {
+		return tickers.Company{Symbol: symbol, Name: symbol}, symbol != ""
+	}
+	return linker.Company(symbol)
+}
+
+// WithTicker narrows a filter to the articles linked to a company
+func WithTicker(filter database.ArticleFilter, symbol string) database.ArticleFilter {
+	term := &search.Term{Field: search.FieldTicker, Value: symbol}
+	if filter.Query == nil {
+		filter.Query = term
+	} else {
+		filter.Query = &search.And{Children: []search.Node{term, filter.Query}}
+	}
+	return filter
+}
+
+// ParseTimelineRange validates a timeline range name such as "3mo"
+func ParseTimelineRange(name string) error {
+	if _, ok := TimelineRanges[name]; !ok {
+		return fmt.Errorf("unknown range %q, expected 1mo, 3mo, 6mo or 1y", name)
+	}
+	return nil
+}
+
+// GetTickerTimeline merges a company's linked articles with its daily price
+// history, oldest day first. News is still returned when prices cannot be
+// fetched, with the reason in PriceError.
+func GetTickerTimeline(company tickers.Company, rangeName string) (*Timeline, error) {
+	if err := ParseTimelineRange(rangeName); err != nil {
+		return nil, err
+	}
+	now := time.Now().In(MarketLocation)
+	start := TimelineRanges[rangeName](now)
+
+	timeline := &Timeline{
+		Symbol: company.Symbol,
+		Name:   company.ShortName(),
+		Range:  rangeName,
+	}
+	days := make(map[string]*TimelineDay)
+	day := func(date string) *TimelineDay {
+		if days[date] == nil {
+			days[date] = &TimelineDay{Date: date, Articles: []models.ArticleDTO{}}
+		}
+		return days[date]
+	}
+
+	prices, err := FetchDailyHistory(company.Symbol, rangeName)
+	if err != nil {
+		timeline.PriceError = err.Error()
+	}
+	for i := range prices {
+		day(prices[i].Date).Price = &prices[i]
+	}
+
+	// Newest first, so each day's articles stay in that order. Bounding the
+	// query by the range keeps the cap from cutting off its older days.
+	filter := database.ArticleFilter{From: start, ByAge: true}
+	articles, _, err := database.GetArticles(1, maxTimelineArticles, WithTicker(filter, company.Symbol))
+	if err != nil {
+		return nil, err
+	}
+	for _, article := range articles {
+		at := articleTime(article).In(MarketLocation)
+		d := day(at.Format("2006-01-02"))
+		d.Articles = append(d.Articles, models.NewArticleDTO(article))
+	}
+
+	timeline.Days = make([]TimelineDay, 0, len(days))
+	for _, d := range days {
+		timeline.Days = append(timeline.Days, *d)
+	}
+	sort.Slice(timeline.Days, func(i, j int) bool { return timeline.Days[i].Date < timeline.Days[j].Date })
+	return timeline, nil
+}
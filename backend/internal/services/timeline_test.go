@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/tickers"
+)
+
+func TestGetTickerTimelineCapsWithinRange(t *testing.T) {
+	openTestDB(t)
+	company := tickers.Company{Symbol: "TCS", Name: "Tata Consultancy Services Ltd"}
+
+	// Prices come from the cache rather than Yahoo
+	priceCacheMu.Lock()
+	priceCache[yahooSymbol(company.Symbol)+"|1mo"] = cachedHistory{fetchedAt: time.Now()}
+	priceCacheMu.Unlock()
+	t.Cleanup(func() {
+		priceCacheMu.Lock()
+		delete(priceCache, yahooSymbol(company.Symbol)+"|1mo")
+		priceCacheMu.Unlock()
+	})
+
+	insert := func(title string, published time.Time) {
+		t.Helper()
+		id, err := database.InsertArticle(models.Article{
+			Title:       title,
+			URL:         "https://example.com/" + title,
+			Source:      models.Source{Name: "Livemint"},
+			PublishedAt: published,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		link := []models.ArticleTicker{{Symbol: company.Symbol, Name: company.Name, Confidence: 1, Mentions: 1}}
+		if err := database.SaveArticleTickers(id, link, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		insert(fmt.Sprintf("old-%d", i), now.AddDate(0, -2, -i))
+	}
+	for i := 0; i < maxTimelineArticles+20; i++ {
+		insert(fmt.Sprintf("recent-%d", i), now.Add(-time.Duration(i+1)*time.Hour))
+	}
+	// Dated by when it was stored, which is now
+	insert("undated", time.Time{})
+
+	timeline, err := GetTickerTimeline(company, "1mo")
+	if err != nil {
+		t.Fatalf("GetTickerTimeline() error = %v", err)
+	}
+
+	start := TimelineRanges["1mo"](now.In(MarketLocation)).Format("2006-01-02")
+	count := 0
+	titles := make(map[string]bool)
+	for _, day := range timeline.Days {
+		if day.Date < start {
+			t.Errorf("day %s is before the range starts on %s", day.Date, start)
+		}
+		for _, article := range day.Articles {
+			titles[article.Title] = true
+			count++
+		}
+	}
+	if count != maxTimelineArticles {
+		t.Errorf("timeline has %d articles, want %d", count, maxTimelineArticles)
+	}
+	for _, title := range []string{"undated", "recent-0", fmt.Sprintf("recent-%d", maxTimelineArticles-2)} {
+		if !titles[title] {
+			t.Errorf("timeline is missing %q", title)
+		}
+	}
+}
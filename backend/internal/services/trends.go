@@ -0,0 +1,331 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+)
+
+// TrendWindows are the rolling windows trends can be requested for
+var TrendWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+}
+
+const (
+	// trendBaseline is how far back a term's usual frequency is measured,
+	// ending where the requested window starts
+	trendBaseline = 7 * 24 * time.Hour
+	// minBurstScore is how many standard deviations above its baseline a term
+	// must be to count as trending
+	minBurstScore = 2
+	// maxTrendWindow is the longest of TrendWindows
+	maxTrendWindow = 24 * time.Hour
+	// trendBucketSize is the granularity counts are kept at
+	trendBucketSize = 10 * time.Minute
+	// maxTrendSamples caps the sample articles kept per term and bucket
+	maxTrendSamples = 3
+)
+
+// minTrendCounts is how many articles must mention a term within a window
+// before it can trend, so a single story cannot make a word trend
+var minTrendCounts = map[time.Duration]int{
+	time.Hour:      3,
+	6 * time.Hour:  3,
+	24 * time.Hour: 4,
+}
+
+// Trend is a term or bigram mentioned unusually often within a window
+type Trend struct {
+	Term     string              `json:"term"`
+	Count    int                 `json:"count"`
+	Expected float64             `json:"expected"`
+	Score    float64             `json:"score"`
+	Articles []models.ArticleDTO `json:"articles"`
+}
+
+// trendBucket counts the articles mentioning each term within trendBucketSize
+type trendBucket struct {
+	counts   map[string]int
+	samples  map[string][]int64 // newest article IDs last
+	articles []int64
+}
+
+// trendTracker keeps bucketed term counts for the baseline period plus the
+// longest window
+type trendTracker struct {
+	mu      sync.Mutex
+	buckets map[int64]*trendBucket // keyed by bucketOf
+	seen    map[int64]bool
+}
+
+var trends = &trendTracker{
+	buckets: make(map[int64]*trendBucket),
+	seen:    make(map[int64]bool),
+}
+
+// LoadTrends counts the stored articles recent enough to affect trends
+func LoadTrends() error {
+	articles, err := database.GetArticlesSince(time.Now().Add(-trendBaseline - maxTrendWindow))
+	if err != nil {
+		return err
+	}
+	for _, article := range articles {
+		trends.add(article, time.Now())
+	}
+	log.Printf("Counted terms of %d recent articles for trends", len(articles))
+	return nil
+}
+
+// TrackTrends counts the terms of a newly stored article
+func TrackTrends(article models.Article) {
+	trends.add(article, time.Now())
+}
+
+// GetTrends returns up to limit terms bursting within the window, each with
+// a few of the articles mentioning it
+func GetTrends(window time.Duration, limit int) ([]Trend, error) {
+	found, samples := trends.trending(window, limit, time.Now())
+
+	var ids []int64
+	for _, trend := range found {
+		ids = append(ids, samples[trend.Term]...)
+	}
+	articles, err := database.GetArticlesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+
+	for i := range found {
+		found[i].Articles = []models.ArticleDTO{}
+		for _, id := range samples[found[i].Term] {
+			if article, ok := byID[id]; ok {
+				found[i].Articles = append(found[i].Articles, models.NewArticleDTO(article))
+			}
+		}
+	}
+	return found, nil
+}
+
+// ParseTrendWindow resolves a window name such as "6h"
+func ParseTrendWindow(name string) (time.Duration, error) {
+	window, ok := TrendWindows[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown window %q, expected 1h, 6h or 24h", name)
+	}
+	return window, nil
+}
+
+// articleTime is when an article appeared, falling back to when it was
+// stored for scrapers that cannot read a publish date
+func articleTime(article models.Article) time.Time {
+	if article.PublishedAt.Year() < 1971 {
+		return article.CreatedAt
+	}
+	return article.PublishedAt
+}
+
+// trendNoise are words so common in market headlines that they never make
+// a meaningful trend on their own
+var trendNoise = map[string]bool{
+	"stock": true, "stocks": true, "share": true, "shares": true, "price": true, "prices": true,
+	"market": true, "markets": true, "today": true, "buy": true, "sell": true, "rs": true,
+	"company": true, "day": true, "time": true, "session": true, "news": true, "live": true,
+	"updates": true, "check": true, "know": true, "top": true, "new": true, "per": true, "cent": true,
+	"week": true, "year": true, "monday": true, "tuesday": true, "wednesday": true,
+	"thursday": true, "friday": true, "saturday": true, "sunday": true, "january": true,
+	"february": true, "march": true, "april": true, "june": true, "july": true, "august": true,
+	"september": true, "october": true, "november": true, "december": true,
+}
+
+// trendTerms returns the distinct words and adjacent word pairs of a
+// headline and description, ignoring stopwords and bare numbers
+func trendTerms(article models.Article) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, text := range []string{article.Title, article.Description} {
+		prev := ""
+		for _, word := range tokenize(text) {
+			if len(word) < 2 || stopwords[word] || trendNoise[word] || isNumber(word) {
+				prev = ""
+				continue
+			}
+			add(word)
+			if prev != "" {
+				add(prev + " " + word)
+			}
+			prev = word
+		}
+	}
+	return terms
+}
+
+func isNumber(word string) bool {
+	return strings.IndexFunc(word, func(r rune) bool { return r < '0' || r > '9' }) < 0
+}
+
+func (t *trendTracker) add(article models.Article, now time.Time) {
+	at := articleTime(article)
+	if at.Before(now.Add(-trendBaseline-maxTrendWindow)) || at.After(now.Add(time.Hour)) {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.seen[article.ID] {
+		return
+	}
+	t.prune(now)
+
+	key := bucketOf(at)
+	bucket := t.buckets[key]
+	if bucket == nil {
+		bucket = &trendBucket{counts: make(map[string]int), samples: make(map[string][]int64)}
+		t.buckets[key] = bucket
+	}
+	for _, term := range trendTerms(article) {
+		bucket.counts[term]++
+		samples := append(bucket.samples[term], article.ID)
+		if len(samples) > maxTrendSamples {
+			samples = samples[1:]
+		}
+		bucket.samples[term] = samples
+	}
+	bucket.articles = append(bucket.articles, article.ID)
+	t.seen[article.ID] = true
+}
+
+// bucketOf returns the key of the bucket a time falls into
+func bucketOf(at time.Time) int64 {
+	return at.Unix() / int64(trendBucketSize/time.Second)
+}
+
+// prune drops buckets that have fallen out of every window and baseline
+func (t *trendTracker) prune(now time.Time) {
+	oldest := bucketOf(now.Add(-trendBaseline - maxTrendWindow))
+	for key, bucket := range t.buckets {
+		if key < oldest {
+			for _, id := range bucket.articles {
+				delete(t.seen, id)
+			}
+			delete(t.buckets, key)
+		}
+	}
+}
+
+// trending scores every term mentioned in the window against its rate over
+// the baseline period and returns the bursting ones with sample article IDs
+func (t *trendTracker) trending(window time.Duration, limit int, now time.Time) ([]Trend, map[string][]int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(now)
+
+	last := bucketOf(now)
+	windowBuckets := int64(window / trendBucketSize)
+	baselineBuckets := int64(trendBaseline / trendBucketSize)
+
+	current := make(map[string]int)
+	baseline := make(map[string]int)
+	var windowArticles, baselineArticles int
+	samples := make(map[string][]int64)
+	for key := last; key > last-windowBuckets-baselineBuckets; key-- {
+		bucket := t.buckets[key]
+		if bucket == nil {
+			continue
+		}
+		inWindow := key > last-windowBuckets
+		if inWindow {
+			windowArticles += len(bucket.articles)
+		} else {
+			baselineArticles += len(bucket.articles)
+		}
+		for term, count := range bucket.counts {
+			if !inWindow {
+				baseline[term] += count
+				continue
+			}
+			current[term] += count
+			// Buckets are walked newest first, so keep the first samples found
+			for i := len(bucket.samples[term]) - 1; i >= 0 && len(samples[term]) < maxTrendSamples; i-- {
+				samples[term] = append(samples[term], bucket.samples[term][i])
+			}
+		}
+	}
+
+	found := []Trend{}
+	minCount := minTrendCounts[window]
+	for term, count := range current {
+		if count < minCount {
+			continue
+		}
+		// Poisson-style z-score against the share of baseline articles that
+		// mention the term, so a busier scrape does not make every word trend.
+		// Add-one smoothing keeps a cold start with no baseline from trending.
+		expected := float64(windowArticles) * float64(baseline[term]+1) / float64(baselineArticles+1)
+		score := (float64(count) - expected) / math.Sqrt(math.Max(expected, 1))
+		if score < minBurstScore {
+			continue
+		}
+		found = append(found, Trend{
+			Term:     term,
+			Count:    count,
+			Expected: math.Round(expected*100) / 100,
+			Score:    math.Round(score*100) / 100,
+		})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score > found[j].Score
+		}
+		return found[i].Term < found[j].Term
+	})
+
+	found = dropCoveredTerms(found)
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	return found, samples
+}
+
+// dropCoveredTerms removes single words that mostly trend as part of a
+// trending bigram, such as "repo" alongside "repo rate"
+func dropCoveredTerms(found []Trend) []Trend {
+	kept := []Trend{}
+	for _, trend := range found {
+		covered := false
+		if !strings.Contains(trend.Term, " ") {
+			for _, k := range found {
+				words := strings.Fields(k.Term)
+				if len(words) == 2 && (words[0] == trend.Term || words[1] == trend.Term) &&
+					float64(k.Count) >= 0.8*float64(trend.Count) {
+					covered = true
+					break
+				}
+			}
+		}
+		if !covered {
+			kept = append(kept, trend)
+		}
+	}
+	return kept
+}
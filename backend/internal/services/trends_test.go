@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"stock-news-aggregator/internal/models"
+)
+
+func TestTrendTrackerBursts(t *testing.T) {
+	now := time.Date(2025, 6, 9, 12, 30, 0, 0, time.UTC)
+	tracker := &trendTracker{buckets: make(map[int64]*trendBucket), seen: make(map[int64]bool)}
+
+	var id int64
+	add := func(title string, age time.Duration) {
+		id++
+		tracker.add(models.Article{ID: id, Title: title, PublishedAt: now.Add(-age)}, now)
+	}
+
+	// "sensex" is mentioned steadily all week; "repo rate" only today
+	for day := 1; day <= 7; day++ {
+		for i := 0; i < 3; i++ {
+			add("Sensex closes flat", time.Duration(day)*24*time.Hour+time.Duration(i)*time.Hour)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		add("RBI cuts repo rate, Sensex cheers", time.Duration(i)*time.Hour)
+	}
+	add("RBI cuts repo rate, Sensex cheers", 30*time.Minute)
+	add("Banks to pass on repo rate cut", 20*time.Minute)
+	// Re-adding an article is ignored
+	tracker.add(models.Article{ID: 1, Title: "RBI cuts repo rate", PublishedAt: now}, now)
+
+	found, samples := tracker.trending(24*time.Hour, 10, now)
+	terms := make(map[string]Trend)
+	for _, trend := range found {
+		terms[trend.Term] = trend
+	}
+
+	if _, ok := terms["repo rate"]; !ok {
+		t.Fatalf("repo rate is not trending: %+v", found)
+	}
+	if terms["repo rate"].Count != 7 {
+		t.Errorf("repo rate count = %d, want 7", terms["repo rate"].Count)
+	}
+	if _, ok := terms["repo"]; ok {
+		t.Errorf("repo trends alongside repo rate: %+v", found)
+	}
+	if _, ok := terms["sensex"]; ok {
+		t.Errorf("sensex trends despite its baseline: %+v", terms["sensex"])
+	}
+	if got := len(samples["repo rate"]); got != maxTrendSamples {
+		t.Errorf("got %d samples for repo rate, want %d", got, maxTrendSamples)
+	}
+	if want := id - 6; samples["repo rate"][0] != want {
+		t.Errorf("newest sample = %d, want %d", samples["repo rate"][0], want)
+	}
+
+	// Three mentions within the last hour are enough for the 1h window
+	found, _ = tracker.trending(time.Hour, 10, now)
+	if len(found) == 0 || found[0].Term != "repo rate" || found[0].Count != 3 {
+		t.Errorf("trending in the last hour = %+v, want repo rate with three mentions", found)
+	}
+
+	// A week later everything has aged out, leaving an empty list for the
+	// response rather than null
+	if found, _ := tracker.trending(24*time.Hour, 10, now.Add(9*24*time.Hour)); found == nil || len(found) != 0 {
+		t.Errorf("trends after a week = %#v, want an empty list", found)
+	}
+	if len(tracker.seen) != 0 {
+		t.Errorf("%d articles still tracked after pruning", len(tracker.seen))
+	}
+}
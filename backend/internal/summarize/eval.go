@@ -0,0 +1,132 @@
+package summarize
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// EvalCase is an article with a human-written reference summary
+type EvalCase struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	Reference string `json:"reference"`
+}
+
+// LoadEvalCases reads a JSON array of evaluation cases
+func LoadEvalCases(path string) ([]EvalCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cases []EvalCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("invalid evaluation cases in %s: %v", path, err)
+	}
+	return cases, nil
+}
+
+// RougeScore is the overlap between a summary and a reference
+type RougeScore struct {
+	Precision float64
+	Recall    float64
+	F1        float64
+}
+
+func rougeScore(overlap, candidate, reference int) RougeScore {
+	var s RougeScore
+	if candidate > 0 {
+		s.Precision = float64(overlap) / float64(candidate)
+	}
+	if reference > 0 {
+		s.Recall = float64(overlap) / float64(reference)
+	}
+	if s.Precision+s.Recall > 0 {
+		s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+	}
+	return s
+}
+
+// evalWords lower-cases text into words for ROUGE
+func evalWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// RougeN scores the n-grams a summary shares with a reference, each counted
+// at most as often as it appears in the reference
+func RougeN(n int, candidate, reference string) RougeScore {
+	grams := func(words []string) map[string]int {
+		counts := make(map[string]int)
+		for i := 0; i+n <= len(words); i++ {
+			counts[strings.Join(words[i:i+n], " ")]++
+		}
+		return counts
+	}
+	c, r := grams(evalWords(candidate)), grams(evalWords(reference))
+
+	var overlap, total, refTotal int
+	for gram, count := range c {
+		total += count
+		overlap += min(count, r[gram])
+	}
+	for _, count := range r {
+		refTotal += count
+	}
+	return rougeScore(overlap, total, refTotal)
+}
+
+// RougeL scores the longest common subsequence of words between a summary
+// and a reference
+func RougeL(candidate, reference string) RougeScore {
+	c, r := evalWords(candidate), evalWords(reference)
+	lcs := make([][]int, len(c)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(r)+1)
+	}
+	for i := 1; i <= len(c); i++ {
+		for j := 1; j <= len(r); j++ {
+			if c[i-1] == r[j-1] {
+				lcs[i][j] = lcs[i-1][j-1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i-1][j], lcs[i][j-1])
+			}
+		}
+	}
+	return rougeScore(lcs[len(c)][len(r)], len(c), len(r))
+}
+
+// EvalReport is a summarizer's mean ROUGE F1 over a set of cases
+type EvalReport struct {
+	Algorithm string
+	Cases     int
+	Rouge1    float64
+	Rouge2    float64
+	RougeL    float64
+}
+
+// Evaluate summarizes every case and averages the ROUGE F1 scores against
+// the references
+func Evaluate(s Summarizer, cases []EvalCase, opts Options) (EvalReport, error) {
+	report := EvalReport{Algorithm: s.Name(), Cases: len(cases)}
+	for _, c := range cases {
+		result, err := s.Summarize(c.Text, opts)
+		if err != nil {
+			return report, fmt.Errorf("%s: %v", c.ID, err)
+		}
+		summary := result.Text()
+		report.Rouge1 += RougeN(1, summary, c.Reference).F1
+		report.Rouge2 += RougeN(2, summary, c.Reference).F1
+		report.RougeL += RougeL(summary, c.Reference).F1
+	}
+	if len(cases) > 0 {
+		n := float64(len(cases))
+		report.Rouge1 /= n
+		report.Rouge2 /= n
+		report.RougeL /= n
+	}
+	return report, nil
+}
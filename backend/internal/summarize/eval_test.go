@@ -0,0 +1,47 @@
+package summarize
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestRouge(t *testing.T) {
+	candidate := "the cat sat on the mat"
+	reference := "the cat lay on the mat"
+
+	if got := RougeN(1, candidate, reference); math.Abs(got.F1-5.0/6) > 1e-9 {
+		t.Errorf("ROUGE-1 F1 = %v, want %v", got.F1, 5.0/6)
+	}
+	if got := RougeN(2, candidate, reference); math.Abs(got.F1-3.0/5) > 1e-9 {
+		t.Errorf("ROUGE-2 F1 = %v, want %v", got.F1, 3.0/5)
+	}
+	if got := RougeL(candidate, reference); math.Abs(got.F1-5.0/6) > 1e-9 {
+		t.Errorf("ROUGE-L F1 = %v, want %v", got.F1, 5.0/6)
+	}
+	if got := RougeN(1, "", reference); got.F1 != 0 {
+		t.Errorf("ROUGE-1 of an empty summary = %v, want 0", got.F1)
+	}
+}
+
+// TestEvaluate compares the summarizers on the financial articles in
+// testdata. Run with -v to see the scores.
+func TestEvaluate(t *testing.T) {
+	cases, err := LoadEvalCases(filepath.Join("testdata", "articles.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("%-10s %8s %8s %8s", "algorithm", "ROUGE-1", "ROUGE-2", "ROUGE-L")
+	for _, name := range Algorithms() {
+		s, _ := Get(name)
+		report, err := Evaluate(s, cases, Options{MaxSentences: 3})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		t.Logf("%-10s %8.3f %8.3f %8.3f", name, report.Rouge1, report.Rouge2, report.RougeL)
+		if report.Rouge1 < 0.3 {
+			t.Errorf("%s ROUGE-1 F1 = %.3f, want at least 0.3", name, report.Rouge1)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package summarize
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Summary formats
+const (
+	FormatParagraph = "paragraph" // the sentences run together
+	FormatBullets   = "bullets"   // one "- " line per sentence
+	FormatTLDR      = "tldr"      // the single best sentence as a headline
+)
+
+// tldrWords caps a TL;DR headline when no word limit is given
+const tldrWords = 25
+
+// ParseFormat validates a format name, defaulting to paragraph for ""
+func ParseFormat(name string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(name)); format {
+	case "":
+		return FormatParagraph, nil
+	case FormatParagraph, FormatBullets, FormatTLDR:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected paragraph, bullets or tldr", name)
+}
+
+// FormatOptions adjusts length options for a format. A TL;DR is one sentence
+// whatever length was asked for.
+func FormatOptions(format string, opts Options) Options {
+	if format == FormatTLDR {
+		opts.MaxSentences, opts.Ratio = 1, 0
+	}
+	return opts
+}
+
+// Format renders summary sentences in a format. A TL;DR is cut at a word
+// boundary to maxWords, or to a headline's length when maxWords is 0, and
+// loses its closing full stop.
+func Format(sentences []string, format string, maxWords int) string {
+	switch format {
+	case FormatBullets:
+		lines := make([]string, len(sentences))
+		for i, sentence := range sentences {
+			lines[i] = "- " + sentence
+		}
+		return strings.Join(lines, "\n")
+	case FormatTLDR:
+		if len(sentences) == 0 {
+			return ""
+		}
+		if maxWords <= 0 {
+			maxWords = tldrWords
+		}
+		words := strings.Fields(sentences[0])
+		if len(words) > maxWords {
+			return strings.TrimRight(strings.Join(words[:maxWords], " "), ",;:") + "…"
+		}
+		return strings.TrimSuffix(strings.Join(words, " "), ".")
+	}
+	return strings.Join(sentences, " ")
+}
@@ -0,0 +1,43 @@
+package summarize
+
+import "math"
+
+// Frequency keeps the first sentence, which usually sets the context, and
+// adds the sentences whose words are most frequent in the whole text
+type Frequency struct{}
+
+func (Frequency) Name() string { return "frequency" }
+
+func (Frequency) Summarize(text string, opts Options) (*Result, error) {
+	sentences, err := sentencesOf(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(sentences) <= SentenceLimit(opts, len(sentences)) && opts.MaxWords == 0 {
+		return topSentences(sentences, make([]float64, len(sentences)), opts), nil
+	}
+
+	// Term counts over the whole text, computed once
+	words := make([][]string, len(sentences))
+	counts := make(map[string]int)
+	for i, sentence := range sentences {
+		words[i] = termsOf(sentence)
+		for _, word := range words[i] {
+			counts[word]++
+		}
+	}
+
+	// Score the rest by the average frequency of their content words
+	scores := make([]float64, len(sentences))
+	scores[0] = math.Inf(1)
+	for i := 1; i < len(sentences); i++ {
+		if len(words[i]) > 0 {
+			total := 0
+			for _, word := range words[i] {
+				total += counts[word]
+			}
+			scores[i] = float64(total) / float64(len(words[i]))
+		}
+	}
+	return topSentences(sentences, scores, opts), nil
+}
@@ -0,0 +1,19 @@
+package summarize
+
+// Lead takes the opening sentences. News is written with the most
+// important facts first, which makes this a strong baseline.
+type Lead struct{}
+
+func (Lead) Name() string { return "lead" }
+
+func (Lead) Summarize(text string, opts Options) (*Result, error) {
+	sentences, err := sentencesOf(text)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(sentences))
+	for i := range scores {
+		scores[i] = float64(len(sentences) - i)
+	}
+	return topSentences(sentences, scores, opts), nil
+}
@@ -0,0 +1,140 @@
+package summarize
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// redundancy is the word overlap above which a sentence is taken to repeat
+// one already in the summary. Sources rewrite the same wire copy, so their
+// versions of a fact share most of their words.
+const redundancy = 0.5
+
+// leadBonus is added to a text's first sentence, and less to later ones,
+// since news leads with the facts
+const leadBonus = 1.0
+
+// DocumentResult is a summary made of sentences taken from several texts
+type DocumentResult struct {
+	Sentences []string
+	Documents []int // which text each sentence is from
+	Indices   []int // position of each sentence in its text
+}
+
+// Text joins the summary sentences
+func (r *DocumentResult) Text() string {
+	return strings.Join(r.Sentences, " ")
+}
+
+// SummarizeDocuments summarizes several texts about the same story, such as
+// the articles different sources wrote about one event. Sentences are scored
+// by how many of the texts share their words, with a bonus for opening
+// sentences, and picked greedily, skipping any that repeat a sentence
+// already picked. The summary keeps the order of the texts and of the
+// sentences within them.
+func SummarizeDocuments(texts []string, opts Options) (*DocumentResult, error) {
+	type candidate struct {
+		doc, index int
+		text       string
+		terms      map[string]bool
+		score      float64
+	}
+
+	var candidates []candidate
+	docFreq := make(map[string]int)
+	for d, text := range texts {
+		split, err := sentencesOf(text)
+		if err != nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for i, sentence := range split {
+			terms := make(map[string]bool)
+			for _, term := range termsOf(sentence) {
+				terms[term] = true
+				if !seen[term] {
+					seen[term] = true
+					docFreq[term]++
+				}
+			}
+			candidates = append(candidates, candidate{doc: d, index: i, text: sentence, terms: terms})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no sentences found in texts")
+	}
+
+	// A word reported by every source is central to the story; one only a
+	// single source mentions is detail
+	for i := range candidates {
+		c := &candidates[i]
+		if len(c.terms) == 0 {
+			continue
+		}
+		var total float64
+		for term := range c.terms {
+			total += float64(docFreq[term])
+		}
+		c.score = total/math.Sqrt(float64(len(c.terms))) + leadBonus/float64(c.index+1)
+	}
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return candidates[order[a]].score > candidates[order[b]].score })
+
+	// Like fitLength, but also skipping sentences that repeat one already
+	// picked
+	n := SentenceLimit(opts, len(candidates))
+	var chosen []int
+	words := 0
+	for _, i := range order {
+		if len(chosen) == n {
+			break
+		}
+		if len(candidates[i].terms) == 0 {
+			continue
+		}
+		count := len(strings.Fields(candidates[i].text))
+		if opts.MaxWords > 0 && len(chosen) > 0 && words+count > opts.MaxWords {
+			continue
+		}
+		repeated := false
+		for _, j := range chosen {
+			if overlap(candidates[i].terms, candidates[j].terms) >= redundancy {
+				repeated = true
+				break
+			}
+		}
+		if !repeated {
+			chosen = append(chosen, i)
+			words += count
+		}
+	}
+
+	// Candidates are in text order already
+	sort.Ints(chosen)
+	result := &DocumentResult{}
+	for _, i := range chosen {
+		result.Sentences = append(result.Sentences, candidates[i].text)
+		result.Documents = append(result.Documents, candidates[i].doc)
+		result.Indices = append(result.Indices, candidates[i].index)
+	}
+	return result, nil
+}
+
+// overlap is the cosine similarity of two sentences' word sets
+func overlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+	return float64(shared) / math.Sqrt(float64(len(a)*len(b)))
+}
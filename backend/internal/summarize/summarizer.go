@@ -0,0 +1,146 @@
+// Package summarize produces extractive summaries of news articles
+package summarize
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"stock-news-aggregator/internal/sentences"
+)
+
+// DefaultAlgorithm is the algorithm used when a request does not name one
+const DefaultAlgorithm = "frequency"
+
+// defaultSentences is the summary length when Options set none
+const defaultSentences = 5
+
+// Options control the length of a summary. MaxSentences takes precedence
+// over Ratio; with neither, summaries have five sentences. MaxWords further
+// caps the total words, though the best sentence is always kept.
+type Options struct {
+	MaxSentences int
+	Ratio        float64 // fraction of the text's sentences, e.g. 0.2
+	MaxWords     int
+}
+
+// Result is a summary made of sentences taken from the text
+type Result struct {
+	Sentences []string
+	Indices   []int // position of each sentence in the text
+}
+
+// Text joins the summary sentences
+func (r *Result) Text() string {
+	return strings.Join(r.Sentences, " ")
+}
+
+// Summarizer picks the sentences that best summarize a text
+type Summarizer interface {
+	Name() string
+	Summarize(text string, opts Options) (*Result, error)
+}
+
+// Versioned is implemented by summarizers whose output depends on
+// configuration, such as a model name, that their name does not capture
+type Versioned interface {
+	Version() string
+}
+
+var algorithms = map[string]Summarizer{
+	"frequency": Frequency{},
+	"textrank":  TextRank{},
+	"lead":      Lead{},
+}
+
+// Register adds a summarizer, replacing any with the same name. It is meant
+// for startup, before summaries are requested.
+func Register(s Summarizer) {
+	algorithms[strings.ToLower(s.Name())] = s
+}
+
+// Get returns the summarizer with the given name, or the default one for ""
+func Get(name string) (Summarizer, error) {
+	if name == "" {
+		name = DefaultAlgorithm
+	}
+	s, ok := algorithms[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q, expected one of %s", name, strings.Join(Algorithms(), ", "))
+	}
+	return s, nil
+}
+
+// Algorithms lists the available algorithm names
+func Algorithms() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sentencesOf splits text into sentences, failing on empty text
+func sentencesOf(text string) ([]string, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty text provided")
+	}
+	split := sentences.Split(text)
+	if len(split) == 0 {
+		return nil, fmt.Errorf("no sentences found in text")
+	}
+	return split, nil
+}
+
+// topSentences keeps the highest scoring sentences that fit the length
+// options, in text order
+func topSentences(sentences []string, scores []float64, opts Options) *Result {
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	chosen := fitLength(order, sentences, opts)
+
+	result := &Result{}
+	for _, i := range chosen {
+		result.Sentences = append(result.Sentences, sentences[i])
+		result.Indices = append(result.Indices, i)
+	}
+	return result
+}
+
+// fitLength takes sentences in ranked order until the summary reaches its
+// sentence limit, skipping any that would take it over the word limit, and
+// returns them in text order
+func fitLength(ranked []int, sentences []string, opts Options) []int {
+	n := SentenceLimit(opts, len(sentences))
+	var chosen []int
+	words := 0
+	for _, i := range ranked {
+		if len(chosen) == n {
+			break
+		}
+		count := len(strings.Fields(sentences[i]))
+		if opts.MaxWords > 0 && len(chosen) > 0 && words+count > opts.MaxWords {
+			continue
+		}
+		chosen = append(chosen, i)
+		words += count
+	}
+	sort.Ints(chosen)
+	return chosen
+}
+
+// SentenceLimit is how many of total sentences a summary may have
+func SentenceLimit(opts Options, total int) int {
+	switch {
+	case opts.MaxSentences > 0:
+		return opts.MaxSentences
+	case opts.Ratio > 0:
+		return int(math.Max(1, math.Ceil(opts.Ratio*float64(total))))
+	}
+	return defaultSentences
+}
@@ -0,0 +1,244 @@
+package summarize
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"stock-news-aggregator/internal/sentences"
+)
+
+const story = "Sensex rose 500 points on Monday. " +
+	"Banks led the Sensex rally as bank stocks gained on rate cut hopes. " +
+	"The weather in Mumbai was pleasant. " +
+	"Bank stocks and the Sensex extended gains after the rate cut. " +
+	"Analysts expect bank stocks to lead the market."
+
+func TestSummarizers(t *testing.T) {
+	for _, name := range Algorithms() {
+		s, err := Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := s.Summarize(story, Options{MaxSentences: 3})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(result.Sentences) != 3 || len(result.Indices) != 3 {
+			t.Errorf("%s returned %d sentences, want 3", name, len(result.Sentences))
+		}
+		if _, err := s.Summarize("   ", Options{}); err == nil {
+			t.Errorf("%s accepted empty text", name)
+		}
+	}
+}
+
+func TestLead(t *testing.T) {
+	result, _ := Lead{}.Summarize(story, Options{MaxSentences: 2})
+	if !reflect.DeepEqual(result.Indices, []int{0, 1}) {
+		t.Errorf("Lead indices = %v, want [0 1]", result.Indices)
+	}
+}
+
+func TestTextRankSkipsOffTopicSentences(t *testing.T) {
+	result, _ := TextRank{}.Summarize(story, Options{MaxSentences: 3})
+	for _, i := range result.Indices {
+		if i == 2 {
+			t.Errorf("TextRank picked the off-topic sentence: %v", result.Sentences)
+		}
+	}
+	for i := 1; i < len(result.Indices); i++ {
+		if result.Indices[i] < result.Indices[i-1] {
+			t.Errorf("TextRank sentences out of text order: %v", result.Indices)
+		}
+	}
+}
+
+func TestGetUnknown(t *testing.T) {
+	if _, err := Get("gpt"); err == nil {
+		t.Error("Get accepted an unknown algorithm")
+	}
+	if s, err := Get(""); err != nil || s.Name() != DefaultAlgorithm {
+		t.Errorf("Get(\"\") = %v, %v, want the default", s, err)
+	}
+}
+
+func TestLengthOptions(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want int
+	}{
+		{Options{}, 5},
+		{Options{MaxSentences: 2}, 2},
+		{Options{Ratio: 0.4}, 2},                   // ceil(0.4 * 5)
+		{Options{Ratio: 0.01}, 1},                  // at least one
+		{Options{MaxSentences: 1, Ratio: 1}, 1},    // sentences win
+		{Options{MaxSentences: 5, MaxWords: 7}, 1}, // only the best fits
+	}
+	for _, name := range Algorithms() {
+		s, _ := Get(name)
+		for _, tt := range tests {
+			result, err := s.Summarize(story, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Sentences) != tt.want {
+				t.Errorf("%s %+v returned %d sentences, want %d", name, tt.opts, len(result.Sentences), tt.want)
+			}
+		}
+	}
+
+	result, _ := Frequency{}.Summarize(story, Options{MaxWords: 25})
+	if words := len(strings.Fields(result.Text())); words > 25 {
+		t.Errorf("summary has %d words, want at most 25: %q", words, result.Text())
+	}
+}
+
+func TestFormat(t *testing.T) {
+	sentences := []string{"Sensex rose 500 points on Monday.", "Banks led the rally."}
+	tests := []struct {
+		format   string
+		maxWords int
+		want     string
+	}{
+		{FormatParagraph, 0, "Sensex rose 500 points on Monday. Banks led the rally."},
+		{FormatBullets, 0, "- Sensex rose 500 points on Monday.\n- Banks led the rally."},
+		{FormatTLDR, 0, "Sensex rose 500 points on Monday"},
+		{FormatTLDR, 3, "Sensex rose 500…"},
+	}
+	for _, tt := range tests {
+		if got := Format(sentences, tt.format, tt.maxWords); got != tt.want {
+			t.Errorf("Format(%s, %d) = %q, want %q", tt.format, tt.maxWords, got, tt.want)
+		}
+	}
+
+	if format, err := ParseFormat(""); err != nil || format != FormatParagraph {
+		t.Errorf("ParseFormat(\"\") = %q, %v, want paragraph", format, err)
+	}
+	if _, err := ParseFormat("html"); err == nil {
+		t.Error("ParseFormat accepted an unknown format")
+	}
+	if opts := FormatOptions(FormatTLDR, Options{Ratio: 0.5}); opts.MaxSentences != 1 || opts.Ratio != 0 {
+		t.Errorf("FormatOptions(tldr) = %+v, want one sentence", opts)
+	}
+}
+
+func TestSummarizeDocuments(t *testing.T) {
+	texts := []string{
+		"HDFC Bank reported a 12% rise in quarterly net profit to ₹16,000 crore. " +
+			"The lender's net interest income grew 10%. The stock closed flat.",
+		"HDFC Bank posted a 12% rise in its quarterly net profit to ₹16,000 crore on Saturday. " +
+			"Asset quality improved, with gross NPAs falling to 1.2%.",
+		"The weather in Mumbai was pleasant on Saturday.",
+	}
+	result, err := SummarizeDocuments(texts, Options{MaxSentences: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sentences) != 3 {
+		t.Fatalf("got %d sentences, want 3: %q", len(result.Sentences), result.Sentences)
+	}
+
+	var profit int
+	for i, sentence := range result.Sentences {
+		if strings.Contains(sentence, "net profit") {
+			profit++
+		}
+		if result.Sentences[i] != sentences.Split(texts[result.Documents[i]])[result.Indices[i]] {
+			t.Errorf("sentence %d is not at document %d index %d", i, result.Documents[i], result.Indices[i])
+		}
+		if i > 0 && (result.Documents[i] < result.Documents[i-1] ||
+			result.Documents[i] == result.Documents[i-1] && result.Indices[i] < result.Indices[i-1]) {
+			t.Errorf("sentences out of order: %v %v", result.Documents, result.Indices)
+		}
+	}
+	if profit != 1 {
+		t.Errorf("summary repeats the profit sentence %d times: %q", profit, result.Sentences)
+	}
+
+	if _, err := SummarizeDocuments([]string{"", " "}, Options{}); err == nil {
+		t.Error("SummarizeDocuments accepted empty texts")
+	}
+}
+
+// longArticle joins the evaluation articles into a text of a few hundred
+// sentences, about the length of a long ET feature
+func longArticle(b *testing.B) string {
+	b.Helper()
+	cases, err := LoadEvalCases(filepath.Join("testdata", "articles.json"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var text strings.Builder
+	for i := 0; i < 5; i++ {
+		for _, c := range cases {
+			text.WriteString(c.Text)
+			text.WriteString(" ")
+		}
+	}
+	return text.String()
+}
+
+// legacyFrequency is the frequency summarizer before term counts were
+// shared across sentences, kept to benchmark against
+func legacyFrequency(text string, n int) []string {
+	sentences := sentences.Split(text)
+	summary := []string{sentences[0]}
+	type scoredSentence struct {
+		sentence string
+		score    float64
+	}
+	scored := make([]scoredSentence, len(sentences)-1)
+	for i, sent := range sentences[1:] {
+		words := strings.Fields(sent)
+		wordFreq := make(map[string]int)
+		for _, word := range strings.Fields(text) {
+			wordFreq[strings.ToLower(strings.Trim(word, ".,!?\"'()[]{}"))]++
+		}
+		var score float64
+		for _, word := range words {
+			score += float64(wordFreq[strings.ToLower(strings.Trim(word, ".,!?\"'()[]{}"))])
+		}
+		scored[i] = scoredSentence{sent, score / float64(len(words))}
+	}
+	for i := 0; i < len(scored)-1; i++ {
+		for j := i + 1; j < len(scored); j++ {
+			if scored[j].score > scored[i].score {
+				scored[i], scored[j] = scored[j], scored[i]
+			}
+		}
+	}
+	for i := 0; i < n-1 && i < len(scored); i++ {
+		summary = append(summary, scored[i].sentence)
+	}
+	return summary
+}
+
+func BenchmarkFrequency(b *testing.B) {
+	text := longArticle(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := (Frequency{}).Summarize(text, Options{MaxSentences: 5}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFrequencyLegacy(b *testing.B) {
+	text := longArticle(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		legacyFrequency(text, 5)
+	}
+}
+
+func BenchmarkTextRank(b *testing.B) {
+	text := longArticle(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := (TextRank{}).Summarize(text, Options{MaxSentences: 5}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package summarize
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// damping is the PageRank probability of following an edge rather than
+	// jumping to a random sentence
+	damping = 0.85
+	// maxIterations and convergence bound the power iteration
+	maxIterations = 100
+	convergence   = 1e-4
+)
+
+// TextRank ranks sentences with PageRank over a graph whose edges are the
+// word overlap between sentences, as in Mihalcea and Tarau (2004). Sentences
+// that share vocabulary with many others are central to the story.
+type TextRank struct{}
+
+func (TextRank) Name() string { return "textrank" }
+
+func (TextRank) Summarize(text string, opts Options) (*Result, error) {
+	sentences, err := sentencesOf(text)
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]map[string]bool, len(sentences))
+	for i, sentence := range sentences {
+		words[i] = contentWords(sentence)
+	}
+
+	// Edges only join sentences that share a word, so keep them as lists
+	type edge struct {
+		to     int
+		weight float64
+	}
+	n := len(sentences)
+	edges := make([][]edge, n)
+	outWeight := make([]float64, n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if w := similarity(words[i], words[j]); w > 0 {
+				edges[i] = append(edges[i], edge{j, w})
+				edges[j] = append(edges[j], edge{i, w})
+				outWeight[i] += w
+				outWeight[j] += w
+			}
+		}
+	}
+
+	scores := make([]float64, n)
+	next := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+	for iter := 0; iter < maxIterations; iter++ {
+		delta := 0.0
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for _, e := range edges[i] {
+				sum += e.weight / outWeight[e.to] * scores[e.to]
+			}
+			next[i] = 1 - damping + damping*sum
+			delta += math.Abs(next[i] - scores[i])
+		}
+		scores, next = next, scores
+		if delta < convergence {
+			break
+		}
+	}
+	return topSentences(sentences, scores, opts), nil
+}
+
+// similarity is the TextRank overlap between two sentences, normalized by
+// their lengths so long sentences are not favoured
+func similarity(a, b map[string]bool) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+	overlap := 0
+	for word := range a {
+		if b[word] {
+			overlap++
+		}
+	}
+	if overlap == 0 {
+		return 0
+	}
+	return float64(overlap) / (math.Log(float64(len(a))) + math.Log(float64(len(b))))
+}
+
+// contentWords returns the distinct terms of a sentence
+func contentWords(sentence string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range termsOf(sentence) {
+		set[word] = true
+	}
+	return set
+}
+
+// termsOf lower-cases a sentence into words, dropping stopwords and single
+// characters
+func termsOf(sentence string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(sentence), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !stopwords[word] && len(word) > 1 {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// stopwords are common English words that say nothing about a sentence's
+// topic
+var stopwords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "also": true, "an": true,
+	"and": true, "any": true, "are": true, "as": true, "at": true, "be": true,
+	"been": true, "before": true, "but": true, "by": true, "can": true, "could": true,
+	"did": true, "do": true, "does": true, "for": true, "from": true, "had": true,
+	"has": true, "have": true, "he": true, "her": true, "his": true, "how": true,
+	"if": true, "in": true, "into": true, "is": true, "it": true, "its": true,
+	"more": true, "most": true, "no": true, "not": true, "of": true, "on": true,
+	"or": true, "other": true, "our": true, "over": true, "said": true, "says": true,
+	"she": true, "so": true, "some": true, "than": true, "that": true, "the": true,
+	"their": true, "them": true, "then": true, "there": true, "these": true,
+	"they": true, "this": true, "those": true, "to": true, "up": true, "was": true,
+	"we": true, "were": true, "what": true, "when": true, "which": true, "while": true,
+	"who": true, "will": true, "with": true, "would": true, "you": true,
+}
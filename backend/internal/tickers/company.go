@@ -0,0 +1,124 @@
+package tickers
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Company is one listed company from the symbol master
+type Company struct {
+	Symbol  string   `json:"symbol"`
+	ISIN    string   `json:"isin,omitempty"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// columnNames maps the accepted header names onto the fields they fill. Both
+// the repo's own symbols.csv and NSE's EQUITY_L.csv layout are understood.
+var columnNames = map[string]string{
+	"symbol":          "symbol",
+	"isin":            "isin",
+	"isin number":     "isin",
+	"name":            "name",
+	"name of company": "name",
+	"aliases":         "aliases",
+}
+
+// LoadCompanies reads a symbol master CSV with a header row naming at least
+// the symbol and name columns. Aliases are separated by "|". The returned
+// fingerprint changes whenever the file's contents do.
+func LoadCompanies(path string) ([]Company, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	hash := sha1.New()
+	companies, err := ReadCompanies(io.TeeReader(f, hash))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return companies, hex.EncodeToString(hash.Sum(nil))[:12], nil
+}
+
+// ReadCompanies parses a symbol master CSV, skipping rows without a symbol
+// or name
+func ReadCompanies(r io.Reader) ([]Company, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		if field, ok := columnNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["symbol"]; !ok {
+		return nil, fmt.Errorf("missing symbol column")
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("missing name column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var companies []Company
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		company := Company{
+			Symbol: strings.ToUpper(field(record, "symbol")),
+			ISIN:   field(record, "isin"),
+			Name:   field(record, "name"),
+		}
+		if company.Symbol == "" || company.Name == "" {
+			continue
+		}
+		for _, alias := range strings.Split(field(record, "aliases"), "|") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				company.Aliases = append(company.Aliases, alias)
+			}
+		}
+		companies = append(companies, company)
+	}
+	return companies, nil
+}
+
+// legalSuffixes are dropped from company names to get the name used in news
+var legalSuffixes = []string{" limited", " ltd.", " ltd"}
+
+// ShortName is the company name without its legal suffix, such as
+// "Reliance Industries" for "Reliance Industries Limited"
+func (c Company) ShortName() string {
+	name := c.Name
+	for _, suffix := range legalSuffixes {
+		if strings.HasSuffix(strings.ToLower(name), suffix) {
+			name = strings.TrimSpace(name[:len(name)-len(suffix)])
+			break
+		}
+	}
+	return name
+}
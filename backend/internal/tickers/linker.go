@@ -0,0 +1,284 @@
+package tickers
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MinConfidence is the lowest confidence a link needs to be kept
+const MinConfidence = 0.5
+
+// Link is a company an article mentions
+type Link struct {
+	Symbol     string
+	Name       string
+	Confidence float64
+	Mentions   int
+}
+
+// Per-mention weights by how a company was named. Mentions are combined with
+// a noisy-or, so repeated weak mentions add up to a confident link.
+const (
+	weightName      = 0.9  // a multi-word company name or alias
+	weightWord      = 0.8  // a distinctive single-word name such as "Infosys"
+	weightAcronym   = 0.8  // an upper-case symbol or acronym such as "RIL"
+	weightAmbiguous = 0.35 // a name that is also a common word, such as "Titan"
+	weightContext   = 0.75 // an ambiguous name followed by a word like "shares"
+	titleBonus      = 0.1  // headline mentions are what the story is about
+)
+
+// commonWords are names that also appear in ordinary writing. On their own
+// they only weakly suggest the company.
+var commonWords = map[string]bool{
+	"titan": true, "trent": true, "eternal": true, "sun": true, "power": true, "grid": true,
+	"bank": true, "life": true, "steel": true, "energy": true, "gold": true, "india": true,
+	"global": true, "capital": true, "future": true, "ideal": true, "vision": true,
+	"apollo": true, "coal": true, "motors": true, "finance": true, "cement": true,
+	"ports": true, "infra": true, "united": true,
+}
+
+// contextWords after an ambiguous name show it refers to a listed company
+var contextWords = map[string]bool{
+	"shares": true, "share": true, "stock": true, "stocks": true, "ltd": true,
+	"limited": true, "q1": true, "q2": true, "q3": true, "q4": true, "results": true,
+	"scrip": true, "counter": true, "stake": true, "ipo": true,
+}
+
+type matchKind int
+
+const (
+	kindName matchKind = iota
+	kindWord
+	kindAcronym
+	kindAmbiguous
+)
+
+// phrase is one way of naming a company, as lower-cased words
+type phrase struct {
+	words  []string
+	symbol string
+	kind   matchKind
+}
+
+// Linker finds the companies mentioned in a piece of text
+type Linker struct {
+	companies map[string]Company  // by symbol
+	phrases   map[string][]phrase // by first word
+	exact     map[string]string   // lower-cased name, alias or symbol -> symbol
+}
+
+// NewLinker indexes the names, aliases and symbols of the given companies
+func NewLinker(companies []Company) *Linker {
+	l := &Linker{
+		companies: make(map[string]Company),
+		phrases:   make(map[string][]phrase),
+		exact:     make(map[string]string),
+	}
+	for _, company := range companies {
+		l.companies[company.Symbol] = company
+		l.exact[strings.ToLower(company.Symbol)] = company.Symbol
+
+		names := append([]string{company.ShortName()}, company.Aliases...)
+		for _, name := range names {
+			l.exact[strings.ToLower(name)] = company.Symbol
+			l.addPhrase(name, company.Symbol)
+		}
+		// Symbols are only matched when written in capitals, since many are
+		// also words; two-letter ones are too short to trust at all
+		if len(company.Symbol) >= 3 && isUpper(company.Symbol) {
+			l.addPhrase(company.Symbol, company.Symbol)
+		}
+	}
+	return l
+}
+
+func (l *Linker) addPhrase(name, symbol string) {
+	tokens := tokenize(name)
+	if len(tokens) == 0 {
+		return
+	}
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.lower
+	}
+
+	p := phrase{words: words, symbol: symbol, kind: kindName}
+	switch {
+	case len(words) == 1 && isUpper(name) && len(words[0]) >= 2:
+		p.kind = kindAcronym
+	case len(words) == 1 && commonWords[words[0]]:
+		p.kind = kindAmbiguous
+	case len(words) == 1:
+		p.kind = kindWord
+	}
+
+	for _, existing := range l.phrases[words[0]] {
+		if existing.symbol == symbol && strings.Join(existing.words, " ") == strings.Join(words, " ") {
+			return
+		}
+	}
+	l.phrases[words[0]] = append(l.phrases[words[0]], p)
+}
+
+// Company returns the company with the given symbol
+func (l *Linker) Company(symbol string) (Company, bool) {
+	company, ok := l.companies[symbol]
+	return company, ok
+}
+
+// Companies returns every known company ordered by symbol
+func (l *Linker) Companies() []Company {
+	companies := make([]Company, 0, len(l.companies))
+	for _, company := range l.companies {
+		companies = append(companies, company)
+	}
+	sort.Slice(companies, func(i, j int) bool { return companies[i].Symbol < companies[j].Symbol })
+	return companies
+}
+
+// Resolve maps a symbol, company name or alias such as "RIL" to its symbol
+func (l *Linker) Resolve(value string) (string, bool) {
+	symbol, ok := l.exact[strings.ToLower(strings.Join(strings.Fields(value), " "))]
+	return symbol, ok
+}
+
+// Link returns the companies mentioned in an article's title and body with
+// at least MinConfidence, most confident first
+func (l *Linker) Link(title, body string) []Link {
+	type evidence struct {
+		miss     float64 // probability every mention so far is a false positive
+		mentions int
+	}
+	found := make(map[string]*evidence)
+
+	for i, text := range []string{title, body} {
+		bonus := 0.0
+		if i == 0 {
+			bonus = titleBonus
+		}
+		for _, m := range l.mentions(tokenize(text)) {
+			e := found[m.symbol]
+			if e == nil {
+				e = &evidence{miss: 1}
+				found[m.symbol] = e
+			}
+			e.miss *= 1 - math.Min(m.weight+bonus, 0.95)
+			e.mentions++
+		}
+	}
+
+	var links []Link
+	for symbol, e := range found {
+		confidence := math.Round((1-e.miss)*100) / 100
+		if confidence < MinConfidence {
+			continue
+		}
+		links = append(links, Link{
+			Symbol:     symbol,
+			Name:       l.companies[symbol].ShortName(),
+			Confidence: confidence,
+			Mentions:   e.mentions,
+		})
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Confidence != links[j].Confidence {
+			return links[i].Confidence > links[j].Confidence
+		}
+		return links[i].Symbol < links[j].Symbol
+	})
+	return links
+}
+
+type mention struct {
+	symbol string
+	weight float64
+}
+
+// mentions scans tokens left to right, taking the longest phrase that
+// matches at each position
+func (l *Linker) mentions(tokens []token) []mention {
+	var found []mention
+	for i := 0; i < len(tokens); {
+		best, bestLen := phrase{}, 0
+		for _, p := range l.phrases[tokens[i].lower] {
+			if len(p.words) > bestLen && l.matches(p, tokens, i) {
+				best, bestLen = p, len(p.words)
+			}
+		}
+		if bestLen == 0 {
+			i++
+			continue
+		}
+
+		var weight float64
+		switch best.kind {
+		case kindName:
+			weight = weightName
+		case kindWord:
+			weight = weightWord
+		case kindAcronym:
+			weight = weightAcronym
+		case kindAmbiguous:
+			weight = weightAmbiguous
+			if next := i + bestLen; next < len(tokens) && contextWords[tokens[next].lower] {
+				weight = weightContext
+			}
+		}
+		found = append(found, mention{symbol: best.symbol, weight: weight})
+		i += bestLen
+	}
+	return found
+}
+
+// matches reports whether phrase p occurs at tokens[i:]. Names must start
+// with a capital letter and acronyms must be written entirely in capitals.
+func (l *Linker) matches(p phrase, tokens []token, i int) bool {
+	if i+len(p.words) > len(tokens) {
+		return false
+	}
+	for j, word := range p.words {
+		if tokens[i+j].lower != word {
+			return false
+		}
+	}
+	first := tokens[i].text
+	if p.kind == kindAcronym {
+		return isUpper(first)
+	}
+	r := []rune(first)[0]
+	return unicode.IsUpper(r) || unicode.IsDigit(r)
+}
+
+type token struct {
+	text  string
+	lower string
+}
+
+// tokenize splits text into words, keeping "&" so names like "M&M" and
+// "Mahindra & Mahindra" survive
+func tokenize(text string) []token {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+	})
+	tokens := make([]token, len(fields))
+	for i, f := range fields {
+		tokens[i] = token{text: f, lower: strings.ToLower(f)}
+	}
+	return tokens
+}
+
+// isUpper reports whether s has letters and all of them are capitals
+func isUpper(s string) bool {
+	hasLetter := false
+	for _, r := range s {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+	return hasLetter
+}
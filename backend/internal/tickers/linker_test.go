@@ -0,0 +1,124 @@
+package tickers
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testSymbols = `symbol,isin,name,aliases
+RELIANCE,INE002A01018,Reliance Industries Limited,RIL
+TCS,INE467B01029,Tata Consultancy Services Limited,TCS
+TATAMOTORS,INE155A01022,Tata Motors Limited,
+INFY,INE009A01021,Infosys Limited,
+TITAN,INE280A01028,Titan Company Limited,Titan
+M&M,INE101A01026,Mahindra & Mahindra Limited,M&M|Mahindra and Mahindra
+SBIN,INE062A01020,State Bank of India,SBI|State Bank
+SBILIFE,INE123W01016,SBI Life Insurance Company Limited,SBI Life
+ITC,INE154A01025,ITC Limited,
+`
+
+func testLinker(t *testing.T) *Linker {
+	t.Helper()
+	companies, err := ReadCompanies(strings.NewReader(testSymbols))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewLinker(companies)
+}
+
+func TestLink(t *testing.T) {
+	linker := testLinker(t)
+	tests := []struct {
+		name  string
+		title string
+		body  string
+		want  []string
+	}{
+		{"full name", "Reliance Industries shares rise 2%", "", []string{"RELIANCE"}},
+		{"alias", "RIL to demerge retail arm", "", []string{"RELIANCE"}},
+		{"acronym must be capitals", "Ril to demerge", "", nil},
+		{"lower-case name", "reliance industries rallies", "", nil},
+		{"bare first word", "Reliance Power shares jump", "", nil},
+		{"symbol in capitals", "INFY ADR slips", "", []string{"INFY"}},
+		{"longest match wins", "SBI Life Q1 profit rises", "", []string{"SBILIFE"}},
+		{"both banks", "SBI Life and SBI report results", "", []string{"SBILIFE", "SBIN"}},
+		{"ampersand names", "M&M and Mahindra & Mahindra", "", []string{"M&M"}},
+		{"written out", "Mahindra and Mahindra sales", "", []string{"M&M"}},
+		{"tata group", "Tata Motors, TCS lead gains", "", []string{"TATAMOTORS", "TCS"}},
+		{"common word alone", "", "A titan of the industry. Titan of the markets.", nil},
+		{"common word once", "", "Titan of industry steps down", nil},
+		{"common word with context", "", "Titan shares gained 3% on Monday", []string{"TITAN"}},
+		{"common word repeated", "Titan rallies", "Titan said sales grew", []string{"TITAN"}},
+		{"body mention", "Markets close higher", "Infosys gained 2%", []string{"INFY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, link := range linker.Link(tt.title, tt.body) {
+				got = append(got, link.Symbol)
+			}
+			// Order is by confidence; compare as sets of symbols
+			if !reflect.DeepEqual(sorted(got), sorted(tt.want)) {
+				t.Errorf("Link(%q, %q) = %v, want %v", tt.title, tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkConfidence(t *testing.T) {
+	linker := testLinker(t)
+
+	links := linker.Link("Infosys Q2 results", "Infosys reported... Infosys shares")
+	if len(links) != 1 || links[0].Mentions != 3 {
+		t.Fatalf("Link() = %+v, want one link with three mentions", links)
+	}
+	once := linker.Link("", "Infosys reported")
+	if len(once) != 1 || once[0].Confidence >= links[0].Confidence {
+		t.Errorf("single body mention confidence %+v not below repeated mentions %+v", once, links)
+	}
+	if links[0].Name != "Infosys" {
+		t.Errorf("Name = %q, want the name without its legal suffix", links[0].Name)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	linker := testLinker(t)
+	tests := map[string]string{
+		"RIL":                 "RELIANCE",
+		"reliance":            "RELIANCE",
+		"Reliance Industries": "RELIANCE",
+		"m&m":                 "M&M",
+		"state  bank":         "SBIN",
+		"infosys":             "INFY",
+	}
+	for value, want := range tests {
+		if got, ok := linker.Resolve(value); !ok || got != want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", value, got, ok, want)
+		}
+	}
+	if got, ok := linker.Resolve("Reliance Power"); ok {
+		t.Errorf("Resolve(Reliance Power) = %q, want no match", got)
+	}
+}
+
+func TestReadCompaniesNSELayout(t *testing.T) {
+	input := "SYMBOL,NAME OF COMPANY, SERIES, DATE OF LISTING, PAID UP VALUE, MARKET LOT, ISIN NUMBER, FACE VALUE\n" +
+		"20MICRONS,20 Microns Limited,EQ,06-OCT-2008,5,1,INE144J01027,5\n"
+	companies, err := ReadCompanies(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Company{{Symbol: "20MICRONS", ISIN: "INE144J01027", Name: "20 Microns Limited"}}
+	if !reflect.DeepEqual(companies, want) {
+		t.Errorf("ReadCompanies() = %+v, want %+v", companies, want)
+	}
+}
+
+func sorted(s []string) []string {
+	out := append([]string(nil), s...)
+	sort.Strings(out)
+	return out
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 	"path/filepath"
 
@@ -13,24 +18,71 @@ import (
 	"stock-news-aggregator/internal/services"
 	"stock-news-aggregator/internal/models"
 	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/search"
 )
 
 type PaginatedResponse struct {
-	Articles    []models.Article `json:"articles"`
+	Articles    []models.ArticleDTO `json:"articles"`
 	TotalCount  int             `json:"totalCount"`
 	CurrentPage int             `json:"currentPage"`
 	PageSize    int             `json:"pageSize"`
 	TotalPages  int             `json:"totalPages"`
+	Facets      *database.Facets `json:"facets,omitempty"`
 }
 
-// SummarizeRequest represents the request body for article summarization
-type SummarizeRequest struct {
-	URL string `json:"url" binding:"required"`
+// SummaryOptionsRequest holds the summary options both summarize endpoints
+// accept. maxSentences takes precedence over ratio; maxWords caps either.
+type SummaryOptionsRequest struct {
+	Algorithm    string  `json:"algorithm" form:"algorithm"` // frequency (default), textrank, lead or llm
+	MaxSentences int     `json:"maxSentences" form:"maxSentences"`
+	Ratio        float64 `json:"ratio" form:"ratio"` // fraction of the article's sentences
+	MaxWords     int     `json:"maxWords" form:"maxWords"`
+	Format       string  `json:"format" form:"format"` // paragraph (default), bullets or tldr
+}
+
+func (r SummaryOptionsRequest) options() services.SummaryOptions {
+	return services.SummaryOptions{
+		Algorithm:    r.Algorithm,
+		MaxSentences: r.MaxSentences,
+		Ratio:        r.Ratio,
+		MaxWords:     r.MaxWords,
+		Format:       r.Format,
+	}
+}
+
+
+// ArticleSummarizeRequest is the optional request body for summarizing a
+// stored article
+type ArticleSummarizeRequest struct {
+	SummaryOptionsRequest
 }
 
 // SummarizeResponse represents the response for article summarization
 type SummarizeResponse struct {
-	Summary string `json:"summary"`
+	Summary   string   `json:"summary"`
+	Format    string   `json:"format"`
+	Algorithm string   `json:"algorithm"` // the extractive fallback if the requested one failed
+	Indices   []int    `json:"indices"`   // positions of the summary's sentences in the source text
+	Sentences []string `json:"sentences"` // the source sentences at those positions, as written there
+	Cached    bool     `json:"cached"`    // served from the summary cache
+}
+
+func newSummarizeResponse(summary *services.Summary) SummarizeResponse {
+	response := SummarizeResponse{
+		Summary:   summary.Text,
+		Format:    summary.Format,
+		Algorithm: summary.Algorithm,
+		Indices:   summary.Indices,
+		Sentences: summary.Sentences,
+		Cached:    summary.Cached,
+	}
+	if response.Indices == nil {
+		response.Indices = []int{}
+	}
+	if response.Sentences == nil {
+		response.Sentences = []string{}
+	}
+	return response
 }
 
 func main() {
@@ -45,8 +97,55 @@ func main() {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// Add the LLM summarizer if one is configured
+	if err := services.LoadLLMSummarizer(); err != nil {
+		log.Printf("Error loading LLM summarizer, using extractive summaries only: %v", err)
+	}
+
 	// Initialize text summarizer
 	summarizer := services.NewTextSummarizer(5) // 5 sentences max
+	if err := services.PruneSummaryCache(); err != nil {
+		log.Printf("Error pruning summary cache: %v", err)
+	}
+
+	// Link stored articles to the companies they mention
+	if err := services.LoadSymbolMaster(services.SymbolsFile()); err != nil {
+		log.Printf("Error loading symbol master, articles will not be linked to companies: %v", err)
+	} else if err := services.BackfillArticleTickers(); err != nil {
+		log.Printf("Error linking stored articles to companies: %v", err)
+	}
+
+	// Classify stored articles into corporate and market events
+	if err := services.LoadEventRules(services.EventRulesFile()); err != nil {
+		log.Printf("Error loading event rules, articles will not be classified: %v", err)
+	} else if err := services.BackfillArticleEvents(); err != nil {
+		log.Printf("Error classifying stored articles: %v", err)
+	}
+
+	// Pull amounts, percentages and price targets out of stored articles
+	if err := services.BackfillArticleFacts(); err != nil {
+		log.Printf("Error extracting facts from stored articles: %v", err)
+	}
+
+	// Score the sentiment of articles stored before the current lexicon
+	if err := services.BackfillSentiment(); err != nil {
+		log.Printf("Error scoring stored articles: %v", err)
+	}
+
+	// Build search suggestions from already stored articles
+	if err := services.RefreshSuggestions(); err != nil {
+		log.Printf("Error building search suggestions: %v", err)
+	}
+
+	// Index stored articles for related-article lookups
+	if err := services.BuildRelatedIndex(); err != nil {
+		log.Printf("Error building related-article index: %v", err)
+	}
+
+	// Count recent articles towards trending topics
+	if err := services.LoadTrends(); err != nil {
+		log.Printf("Error loading trends: %v", err)
+	}
 
 	// Run initial scraping
 	log.Println("Starting initial news scraping...")
@@ -59,7 +158,7 @@ func main() {
 	// Configure CORS with more permissive settings
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{
 			"Origin",
 			"Content-Type",
@@ -76,26 +175,37 @@ func main() {
 	// Routes
 	router.GET("/api/news", getNews)           // Keep old endpoint for compatibility
 	router.GET("/api/news/db", getNewsFromDB)  // New endpoint for database-backed news
+	router.GET("/api/news/:id", getArticle)
+	router.GET("/api/news/:id/related", getRelatedArticles)
 	router.GET("/api/market-indices", getMarketIndices)
-	router.POST("/api/summarize", func(c *gin.Context) {
-		var req SummarizeRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-			return
-		}
-
-		summary, err := summarizer.SummarizeURL(req.URL)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		c.JSON(http.StatusOK, SummarizeResponse{Summary: summary})
-	})
+	router.GET("/api/search/suggest", getSearchSuggestions)
+	router.GET("/api/trends", getTrends)
+	router.GET("/api/events", getEventTypes)
+	router.GET("/api/tickers/:symbol/news", getTickerNews)
+	router.GET("/api/tickers/:symbol/timeline", getTickerTimeline)
+	router.GET("/api/tickers/:symbol/sentiment", getTickerSentiment)
+	router.GET("/api/sentiment/sources", getSourceSentiment)
+	router.GET("/api/digest", getDigest)
+	router.POST("/api/saved-searches", createSavedSearch)
+	router.GET("/api/saved-searches", getSavedSearches)
+	router.DELETE("/api/saved-searches/:id", deleteSavedSearch)
+	router.GET("/api/alerts", getAlerts)
+
+	// Admin routes
+	admin := router.Group("/api/admin", requireAdminToken())
+	admin.GET("/db-stats", getDatabaseStats)
+	router.POST("/api/summarize", summarizeHandler(summarizer))
+	router.POST("/api/news/:id/summarize", summarizeArticleHandler(summarizer))
+
+	// Pre-summarize stored and newly scraped articles in background
+	services.StartSummaryWorkers(summarizer, services.SummaryWorkers())
 
 	// Start periodic scraping in background
 	go startPeriodicScraping()
 
+	// Start daily retention in background
+	go startPeriodicRetention(filepath.Join("data", "archive.db"))
+
 	log.Printf("Starting server on :8080")
 	log.Fatal(router.Run(":8080"))
 }
@@ -150,7 +260,7 @@ func getNews(c *gin.Context) {
 
 	// Return paginated response
 	c.JSON(http.StatusOK, PaginatedResponse{
-		Articles:    pagedArticles,
+		Articles:    models.NewArticleDTOs(pagedArticles),
 		TotalCount:  totalCount,
 		CurrentPage: page,
 		PageSize:    pageSize,
@@ -159,10 +269,50 @@ func getNews(c *gin.Context) {
 }
 
 func getNewsFromDB(c *gin.Context) {
-	// Get pagination and search parameters from query
+	filter, ok := bindArticleFilter(c)
+	if !ok {
+		return
+	}
+
+	// Completed searches feed the popular query suggestions. Only the first
+	// page counts, so paging through results is not a repeat search.
+	if page, _ := strconv.Atoi(c.DefaultQuery("page", "1")); page <= 1 {
+		if filter.Query != nil {
+			services.RecordSearchQuery(c.Query("q"))
+		} else if filter.Search != "" {
+			services.RecordSearchQuery(filter.Search)
+		}
+	}
+
+	writeNewsPage(c, filter)
+}
+
+// bindArticleFilter parses the article filter parameters, writing a 400
+// response and returning false if they are invalid
+func bindArticleFilter(c *gin.Context) (database.ArticleFilter, bool) {
+	filter, err := parseArticleFilter(c)
+	if err != nil {
+		var parseErr *search.ParseError
+		if errors.As(err, &parseErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"details": parseErr,
+				"query":   c.Query("q"),
+			})
+			return filter, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	return filter, true
+}
+
+// writeNewsPage responds with one page of the stored articles matching the
+// filter, honouring the page, pageSize, balance, seed and facets parameters
+func writeNewsPage(c *gin.Context, filter database.ArticleFilter) {
+	// Get pagination parameters from query
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-	search := c.Query("search")
 
 	// Ensure valid pagination values
 	if page < 1 {
@@ -175,8 +325,27 @@ func getNewsFromDB(c *gin.Context) {
 		pageSize = 50 // Maximum page size
 	}
 
-	// Fetch news from database with search
-	articles, totalCount, err := services.GetNewsFromDB(page, pageSize, search)
+	// Sources are interleaved unless balance=false; seed permutes their turn order
+	var balance services.BalanceOptions
+	if value := c.Query("balance"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid balance value"})
+			return
+		}
+		balance.Disabled = !enabled
+	}
+	if value := c.Query("seed"); value != "" {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seed value"})
+			return
+		}
+		balance.Seed = seed
+	}
+
+	// Fetch news from database with filters
+	articles, totalCount, err := services.GetNewsFromDB(page, pageSize, filter, balance)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -185,16 +354,210 @@ func getNewsFromDB(c *gin.Context) {
 	// Calculate total pages
 	totalPages := (totalCount + pageSize - 1) / pageSize
 
+	// Facet counts for the same filter are only computed on request
+	var facets *database.Facets
+	if withFacets, _ := strconv.ParseBool(c.Query("facets")); withFacets {
+		facets, err = services.GetNewsFacets(filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	// Return paginated response
 	c.JSON(http.StatusOK, PaginatedResponse{
-		Articles:    articles,
+		Articles:    models.NewArticleDTOs(articles),
 		TotalCount:  totalCount,
 		CurrentPage: page,
 		PageSize:    pageSize,
 		TotalPages:  totalPages,
+		Facets:      facets,
+	})
+}
+
+func getSearchSuggestions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "8"))
+	if limit < 1 {
+		limit = 8
+	}
+	if limit > 20 {
+		limit = 20 // Maximum number of suggestions
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":       c.Query("q"),
+		"suggestions": services.Suggest(c.Query("q"), limit),
+	})
+}
+
+func getTrends(c *gin.Context) {
+	windowName := c.DefaultQuery("window", "24h")
+	window, err := services.ParseTrendWindow(windowName)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50 // Maximum number of trends
+	}
+
+	trends, err := services.GetTrends(window, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"window": windowName,
+		"trends": trends,
+	})
+}
+
+func getEventTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"events": services.EventTypes()})
+}
+
+func getArticle(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	article, err := services.GetArticle(id)
+	if err == database.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewArticleDetailDTO(*article))
+}
+
+func getRelatedArticles(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit < 1 {
+		limit = 5
+	}
+	if limit > 20 {
+		limit = 20 // Maximum number of related articles
+	}
+
+	related, err := services.GetRelatedArticles(id, limit)
+	if err == database.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"articleId": id,
+		"related":   related,
 	})
 }
 
+// parseArticleFilter reads the q, search, source, from, to, sort and
+// hasContent query parameters. Sources may be repeated or comma-separated and
+// accept short names such as "ET".
+func parseArticleFilter(c *gin.Context) (database.ArticleFilter, error) {
+	filter := database.ArticleFilter{
+		Search: c.Query("search"),
+		Sort:   c.DefaultQuery("sort", database.SortPublished),
+	}
+
+	if q := c.Query("q"); q != "" {
+		query, err := services.ParseQuery(q)
+		if err != nil {
+			return filter, err
+		}
+		filter.Query = query
+	}
+
+	for _, value := range c.QueryArray("source") {
+		for _, source := range strings.Split(value, ",") {
+			if strings.TrimSpace(source) != "" {
+				filter.Sources = append(filter.Sources, services.CanonicalSource(source))
+			}
+		}
+	}
+
+	for _, value := range c.QueryArray("event") {
+		for _, event := range strings.Split(value, ",") {
+			if strings.TrimSpace(event) == "" {
+				continue
+			}
+			eventType, err := services.ParseEventType(event)
+			if err != nil {
+				return filter, err
+			}
+			filter.Events = append(filter.Events, eventType)
+		}
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = parseDateParam(from, false); err != nil {
+			return filter, fmt.Errorf("invalid from date: %s", from)
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = parseDateParam(to, true); err != nil {
+			return filter, fmt.Errorf("invalid to date: %s", to)
+		}
+	}
+
+	switch filter.Sort {
+	case database.SortPublished, database.SortRelevance, database.SortScraped:
+	default:
+		return filter, fmt.Errorf("invalid sort: %s (expected published, relevance or scraped)", filter.Sort)
+	}
+
+	if hasContent := c.Query("hasContent"); hasContent != "" {
+		value, err := strconv.ParseBool(hasContent)
+		if err != nil {
+			return filter, fmt.Errorf("invalid hasContent: %s", hasContent)
+		}
+		filter.HasContent = &value
+	}
+
+	return filter, nil
+}
+
+// parseDateParam accepts RFC 3339 timestamps or plain dates, which are taken
+// in Indian Standard Time. A plain date used as an upper bound covers that
+// whole day.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, services.MarketLocation)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
 func startPeriodicScraping() {
 	ticker := time.NewTicker(15 * time.Minute)
 	defer ticker.Stop()
@@ -207,6 +570,56 @@ func startPeriodicScraping() {
 	}
 }
 
+func startPeriodicRetention(archivePath string) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := services.RunRetention(archivePath); err != nil {
+			log.Printf("Error during retention pass: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// requireAdminToken guards admin routes with the ADMIN_TOKEN bearer token.
+// Without a token configured the routes are disabled.
+func requireAdminToken() gin.HandlerFunc {
+	token := os.Getenv("ADMIN_TOKEN")
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Admin API is disabled; set ADMIN_TOKEN to enable it"})
+			return
+		}
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func getDatabaseStats(c *gin.Context) {
+	stats, err := database.GetDatabaseStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	runs, err := database.GetRetentionRuns(20)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"database":      stats,
+		"retentionRuns": runs,
+		"policies":      services.RetentionPoliciesFromEnv(),
+	})
+}
+
 func getMarketIndices(c *gin.Context) {
 	indices, err := services.FetchMarketIndices()
 	if err != nil {
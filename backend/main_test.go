@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/services"
+)
+
+// getNewsPage requests a page of news and decodes a successful response
+func getNewsPage(t *testing.T, router *gin.Engine, path string) (int, PaginatedResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	var response PaginatedResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("invalid response %s: %v", w.Body.String(), err)
+		}
+	}
+	return w.Code, response
+}
+
+func TestParseDateParam(t *testing.T) {
+	tests := []struct {
+		value    string
+		endOfDay bool
+		want     time.Time
+	}{
+		// Plain dates are IST days, and a whole day as an upper bound
+		{"2026-10-18", false, time.Date(2026, 10, 17, 18, 30, 0, 0, time.UTC)},
+		{"2026-10-18", true, time.Date(2026, 10, 18, 18, 30, 0, 0, time.UTC)},
+		{"2026-10-18T09:15:00Z", true, time.Date(2026, 10, 18, 9, 15, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseDateParam(tt.value, tt.endOfDay)
+		if err != nil {
+			t.Errorf("parseDateParam(%q, %v) error = %v", tt.value, tt.endOfDay, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDateParam(%q, %v) = %v, want %v", tt.value, tt.endOfDay, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"18-10-2026", "2026-13-01", "yesterday"} {
+		if _, err := parseDateParam(value, false); err == nil {
+			t.Errorf("parseDateParam(%q) accepted an invalid date", value)
+		}
+	}
+}
+
+func TestNewsFilters(t *testing.T) {
+	openTestDB(t)
+	if err := services.LoadEventRules(""); err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.GET("/api/news/db", getNewsFromDB)
+
+	for i, article := range []models.Article{
+		{Title: "Late on the 17th", Description: "Filed before midnight", Source: models.Source{Name: "Economic Times"},
+			PublishedAt: time.Date(2026, 10, 17, 18, 0, 0, 0, time.UTC)},
+		{Title: "Just after midnight", Content: "Body", Source: models.Source{Name: "Livemint"},
+			PublishedAt: time.Date(2026, 10, 17, 19, 0, 0, 0, time.UTC)},
+		{Title: "Late on the 18th", Content: "Body", Source: models.Source{Name: "Economic Times"},
+			PublishedAt: time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC)},
+		{Title: "Midnight on the 19th", Source: models.Source{Name: "Livemint"},
+			PublishedAt: time.Date(2026, 10, 18, 18, 30, 0, 0, time.UTC)},
+	} {
+		article.URL = "https://example.com/" + article.Title
+		id, err := database.InsertArticle(article)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if article.Title == "Late on the 18th" {
+			if err := database.SaveArticleEvents(id, []models.ArticleEvent{{Type: "earnings", Score: 3}}, "test"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// Scraped in the reverse of publish order
+		if _, err := database.GetDB().Exec(`UPDATE articles SET last_scraped_at = datetime('now', ?) WHERE id = ?`,
+			fmt.Sprintf("-%d minutes", i), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// IST days, with to covering the whole of its day
+		{"from=2026-10-18&to=2026-10-18", []string{"Late on the 18th", "Just after midnight"}},
+		{"to=2026-10-18", []string{"Late on the 18th", "Just after midnight", "Late on the 17th"}},
+		{"from=2026-10-18T00:00:00%2B05:30", []string{"Midnight on the 19th", "Late on the 18th", "Just after midnight"}},
+		{"source=ET", []string{"Late on the 18th", "Late on the 17th"}},
+		{"source=Livemint,ET", []string{"Midnight on the 19th", "Late on the 18th", "Just after midnight", "Late on the 17th"}},
+		{"hasContent=true", []string{"Late on the 18th", "Just after midnight"}},
+		{"hasContent=false", []string{"Midnight on the 19th", "Late on the 17th"}},
+		{"event=earnings", []string{"Late on the 18th"}},
+		{"event=dividend,earnings", []string{"Late on the 18th"}},
+		{"event=ipo", []string{}},
+		{"sort=published", []string{"Midnight on the 19th", "Late on the 18th", "Just after midnight", "Late on the 17th"}},
+		{"sort=scraped", []string{"Late on the 17th", "Just after midnight", "Late on the 18th", "Midnight on the 19th"}},
+		// Title matches outrank description matches, then the newest first
+		{"sort=relevance&search=midnight", []string{"Midnight on the 19th", "Just after midnight", "Late on the 17th"}},
+	}
+	for _, tt := range tests {
+		code, response := getNewsPage(t, router, "/api/news/db?balance=false&"+tt.query)
+		if code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", tt.query, code)
+			continue
+		}
+		got := []string{}
+		for _, article := range response.Articles {
+			got = append(got, article.Title)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	for _, query := range []string{"from=2026-13-01", "to=18-10-2026", "sort=oldest", "hasContent=maybe", "event=rumour"} {
+		if code, _ := getNewsPage(t, router, "/api/news/db?"+query); code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, code)
+		}
+	}
+}
+
+func TestGetArticle(t *testing.T) {
+	openTestDB(t)
+	router := gin.New()
+	router.GET("/api/news/:id", getArticle)
+
+	published := time.Date(2026, 10, 18, 4, 30, 0, 0, time.UTC)
+	id, err := database.InsertArticle(models.Article{
+		Title:       "Reliance Q2 profit rises 9%",
+		Description: "Net profit rose on retail growth",
+		Content:     "Reliance Industries reported a net profit of ₹19,000 crore.",
+		URL:         "https://economictimes.indiatimes.com/markets/stocks/news/reliance-q2-profit-rises-on-retail/articleshow/1.cms",
+		ImageURL:    "https://img.example.com/reliance.jpg",
+		Source:      models.Source{Name: "Economic Times"},
+		PublishedAt: published,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickers := []models.ArticleTicker{{Symbol: "RELIANCE", Name: "Reliance Industries Limited", Confidence: 0.9, Mentions: 2}}
+	events := []models.ArticleEvent{{Type: "earnings", Score: 4}}
+	facts := []models.ArticleFact{{Kind: "amount", Label: "net profit", Value: 190000000000, Amount: 19000, Scale: "crore", Unit: "INR",
+		Text: "₹19,000 crore", Context: "Reliance Industries reported a net profit of ₹19,000 crore.", Symbol: "RELIANCE"}}
+	sentiment := models.ArticleSentiment{Score: 0.5, Label: "positive"}
+	if err := database.SaveArticleTickers(id, tickers, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SaveArticleEvents(id, events, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SaveArticleFacts(id, facts, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SaveArticleSentiment(id, sentiment, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/news/%d", id), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var got models.ArticleDetailDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid response %s: %v", w.Body.String(), err)
+	}
+
+	if got.CreatedAt.IsZero() || got.LastScrapedAt.IsZero() {
+		t.Errorf("createdAt %v, lastScrapedAt %v, want both set", got.CreatedAt, got.LastScrapedAt)
+	}
+	want := models.ArticleDetailDTO{
+		ArticleDTO: models.ArticleDTO{
+			ID:          id,
+			Title:       "Reliance Q2 profit rises 9%",
+			Description: "Net profit rose on retail growth",
+			URL:         "https://economictimes.indiatimes.com/markets/stocks/news/reliance-q2-profit-rises-on-retail/articleshow/1.cms",
+			ImageURL:    "https://img.example.com/reliance.jpg",
+			Source:      models.Source{Name: "Economic Times"},
+			Section:     "markets/stocks",
+			PublishedAt: published,
+			CreatedAt:   got.CreatedAt,
+			Tickers:     tickers,
+			Events:      events,
+			Sentiment:   &sentiment,
+		},
+		Content:       "Reliance Industries reported a net profit of ₹19,000 crore.",
+		LastScrapedAt: got.LastScrapedAt,
+		Facts:         facts,
+	}
+	got.PublishedAt = got.PublishedAt.UTC()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("article = %+v\nwant %+v", got, want)
+	}
+
+	// An article with no facts still has a list
+	plain, err := database.InsertArticle(models.Article{Title: "Markets close flat", URL: "https://example.com/flat",
+		Source: models.Source{Name: "Livemint"}, PublishedAt: published})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/news/%d", plain), nil))
+	if !strings.Contains(w.Body.String(), `"facts":[]`) {
+		t.Errorf("article without facts = %s, want an empty facts list", w.Body.String())
+	}
+
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{"/api/news/abc", http.StatusBadRequest},
+		{"/api/news/999", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, tt.want)
+		}
+	}
+}
+
+func TestRequireAdminToken(t *testing.T) {
+	for _, tt := range []struct {
+		token, header string
+		want          int
+	}{
+		// Admin routes are disabled until a token is configured
+		{"", "", http.StatusServiceUnavailable},
+		{"", "Bearer ", http.StatusServiceUnavailable},
+		{"secret", "", http.StatusUnauthorized},
+		{"secret", "Bearer wrong", http.StatusUnauthorized},
+		{"secret", "secret", http.StatusUnauthorized},
+		{"secret", "Bearer secret", http.StatusOK},
+	} {
+		t.Setenv("ADMIN_TOKEN", tt.token)
+		router := gin.New()
+		router.GET("/api/admin/ping", requireAdminToken(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/ping", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("token %q, header %q: status = %d, want %d", tt.token, tt.header, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"stock-news-aggregator/internal/services"
+)
+
+// sentimentDays reads the days query parameter, defaulting to 30
+func sentimentDays(c *gin.Context) int {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if days < 1 {
+		days = 30
+	}
+	if days > services.MaxSentimentDays {
+		days = services.MaxSentimentDays
+	}
+	return days
+}
+
+func getTickerSentiment(c *gin.Context) {
+	company, ok := bindCompany(c)
+	if !ok {
+		return
+	}
+
+	days := sentimentDays(c)
+	sentiment, err := services.GetTickerSentiment(company.Symbol, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"symbol": company.Symbol,
+		"name":   company.ShortName(),
+		"days":   days,
+		"daily":  sentiment,
+	})
+}
+
+func getSourceSentiment(c *gin.Context) {
+	days := sentimentDays(c)
+	sentiment, err := services.GetSourceSentiment(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"days":    days,
+		"sources": sentiment,
+	})
+}
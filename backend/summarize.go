@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/services"
+)
+
+// SummarizeRequest represents the request body for summarization. Exactly
+// one of the URL, the text or an uploaded file is summarized. Uploads are
+// sent as multipart/form-data in the "file" field, with the options as
+// form fields.
+type SummarizeRequest struct {
+	URL  string `json:"url" form:"url"`
+	Text string `json:"text" form:"text"`
+	SummaryOptionsRequest
+}
+
+// uploadOverhead allows for the multipart framing and option fields around
+// an uploaded document
+const uploadOverhead = 64 << 10
+
+func summarizeHandler(summarizer *services.TextSummarizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxDocumentBytes+uploadOverhead)
+
+		var req SummarizeRequest
+		bind := c.ShouldBindJSON
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			bind = c.ShouldBind
+		}
+		if err := bind(&req); err != nil {
+			if isTooLarge(err) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		text, hasText, ok := summarizeInput(c, req)
+		if !ok {
+			return
+		}
+
+		if err := services.ValidateSummaryOptions(req.options()); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !hasText {
+			summary, err := services.SummarizeURLCached(summarizer, req.options(), req.URL)
+			if err != nil {
+				status := http.StatusBadGateway
+				if services.IsFetchRefused(err) {
+					status = http.StatusBadRequest
+				}
+				c.JSON(status, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusOK, newSummarizeResponse(summary))
+			return
+		}
+
+		if strings.TrimSpace(text) == "" {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No text found to summarize"})
+			return
+		}
+		summary, err := services.SummarizeTextCached(summarizer, req.options(), text)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, newSummarizeResponse(summary))
+	}
+}
+
+// summarizeArticleHandler summarizes a stored article from its stored text
+func summarizeArticleHandler(summarizer *services.TextSummarizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+			return
+		}
+
+		// The body is optional
+		var req ArticleSummarizeRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+				return
+			}
+		}
+		if err := services.ValidateSummaryOptions(req.options()); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		article, err := services.GetArticle(id)
+		if err == database.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		summary, err := services.SummarizeArticle(summarizer, req.options(), *article)
+		if err == services.ErrNoArticleText {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, newSummarizeResponse(summary))
+	}
+}
+
+// summarizeInput checks that the request gives exactly one of a URL, text or
+// an uploaded file and returns the text to summarize, if it is not a URL.
+// It writes the error response and returns ok false otherwise.
+func summarizeInput(c *gin.Context, req SummarizeRequest) (text string, hasText, ok bool) {
+	var file []byte
+	var filename, contentType string
+	hasFile := false
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil && err != http.ErrMissingFile {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file upload"})
+			return "", false, false
+		}
+		if header != nil {
+			if header.Size > services.MaxDocumentBytes {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+				return "", false, false
+			}
+			f, err := header.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file upload"})
+				return "", false, false
+			}
+			defer f.Close()
+			if file, err = io.ReadAll(f); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file upload"})
+				return "", false, false
+			}
+			filename, contentType, hasFile = header.Filename, header.Header.Get("Content-Type"), true
+		}
+	}
+
+	given := 0
+	for _, present := range []bool{req.URL != "", req.Text != "", hasFile} {
+		if present {
+			given++
+		}
+	}
+	if given != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give exactly one of url, text or file"})
+		return "", false, false
+	}
+
+	switch {
+	case req.Text != "":
+		if len(req.Text) > services.MaxDocumentBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Text is too large"})
+			return "", false, false
+		}
+		return req.Text, true, true
+	case hasFile:
+		text, err := services.DocumentText(filename, contentType, file)
+		if errors.Is(err, services.ErrUnsupportedDocument) {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+			return "", false, false
+		}
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return "", false, false
+		}
+		return text, true, true
+	}
+	return "", false, true
+}
+
+func isTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"stock-news-aggregator/internal/database"
+	"stock-news-aggregator/internal/models"
+	"stock-news-aggregator/internal/services"
+)
+
+const testArticleContent = `Tata Motors reported a sharp rise in quarterly profit on Friday. ` +
+	`Jaguar Land Rover sales drove most of the growth in the quarter. ` +
+	`The company said demand for its electric vehicles stayed strong. ` +
+	`Analysts expect margins to improve as commodity costs ease. ` +
+	`Shares of Tata Motors rose 3% after the results. ` +
+	`The board also approved a plan to split the company into two listed entities. ` +
+	`The demerger is expected to be completed within a year.`
+
+// openTestDB opens a fresh database for one test
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := database.InitDB(filepath.Join(t.TempDir(), "news.db")); err != nil {
+		t.Fatalf("InitDB() error = %v", err)
+	}
+	t.Cleanup(func() { database.GetDB().Close() })
+	gin.SetMode(gin.TestMode)
+}
+
+// testRouter serves the summarize endpoints from a fresh database
+func testRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	openTestDB(t)
+
+	summarizer := services.NewTextSummarizer(5)
+	router := gin.New()
+	router.POST("/api/summarize", summarizeHandler(summarizer))
+	router.POST("/api/news/:id/summarize", summarizeArticleHandler(summarizer))
+	return router
+}
+
+// serve sends a request to the router and decodes a successful response
+func serve(t *testing.T, router *gin.Engine, req *http.Request) (int, SummarizeResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var response SummarizeResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("invalid response %s: %v", w.Body.String(), err)
+		}
+	}
+	return w.Code, response
+}
+
+func TestSummarizeArticleHandler(t *testing.T) {
+	router := testRouter(t)
+	id, err := database.InsertArticle(models.Article{
+		Title:       "Tata Motors profit jumps",
+		URL:         "https://example.com/tata-motors",
+		Source:      models.Source{Name: "Livemint"},
+		Content:     testArticleContent,
+		PublishedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/news/" + strconv.FormatInt(id, 10) + "/summarize"
+
+	code, first := serve(t, router, httptest.NewRequest(http.MethodPost, path, nil))
+	if code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", code)
+	}
+	if first.Cached || first.Summary == "" {
+		t.Errorf("first response = %+v, want a fresh summary", first)
+	}
+
+	code, second := serve(t, router, httptest.NewRequest(http.MethodPost, path, nil))
+	if code != http.StatusOK {
+		t.Fatalf("second request status = %d, want 200", code)
+	}
+	if !second.Cached || second.Summary != first.Summary {
+		t.Errorf("second response = %+v, want the cached summary %q", second, first.Summary)
+	}
+
+	// The summarized sentences are returned as they appear in the article,
+	// from the cache too
+	if len(first.Sentences) == 0 || len(first.Sentences) != len(first.Indices) {
+		t.Errorf("first response has %d sentences for %d indices", len(first.Sentences), len(first.Indices))
+	}
+	for _, sentence := range first.Sentences {
+		if !strings.Contains(testArticleContent, sentence) {
+			t.Errorf("sentence %q is not in the article", sentence)
+		}
+	}
+	if !reflect.DeepEqual(second.Sentences, first.Sentences) || !reflect.DeepEqual(second.Indices, first.Indices) {
+		t.Errorf("cached sentences %q at %v, want %q at %v", second.Sentences, second.Indices, first.Sentences, first.Indices)
+	}
+
+	// Other options are a different summary
+	body := strings.NewReader(`{"maxSentences": 1}`)
+	code, other := serve(t, router, httptest.NewRequest(http.MethodPost, path, body))
+	if code != http.StatusOK || other.Cached {
+		t.Errorf("request with options: status %d, cached %v, want 200 and a fresh summary", code, other.Cached)
+	}
+
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{"/api/news/abc/summarize", http.StatusBadRequest},
+		{"/api/news/999/summarize", http.StatusNotFound},
+	} {
+		if code, _ := serve(t, router, httptest.NewRequest(http.MethodPost, tt.path, nil)); code != tt.want {
+			t.Errorf("POST %s status = %d, want %d", tt.path, code, tt.want)
+		}
+	}
+}
+
+// jsonRequest posts a JSON body to the summarize endpoint
+func jsonRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/summarize", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// uploadRequest posts a multipart form with an optional file to the
+// summarize endpoint
+func uploadRequest(t *testing.T, fields map[string]string, filename, contentType string, file []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if filename != "" {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+		header.Set("Content-Type", contentType)
+		part, err := form.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(file)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/summarize", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestSummarizeHandlerText(t *testing.T) {
+	router := testRouter(t)
+	body, _ := json.Marshal(map[string]interface{}{"text": testArticleContent, "maxSentences": 2, "format": "bullets"})
+
+	code, first := serve(t, router, jsonRequest(string(body)))
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if first.Format != "bullets" || len(first.Indices) != 2 || len(first.Sentences) != 2 || first.Cached {
+		t.Errorf("response = %+v, want 2 fresh bullets", first)
+	}
+	if !strings.HasPrefix(first.Summary, "- ") {
+		t.Errorf("summary %q is not bulleted", first.Summary)
+	}
+
+	code, second := serve(t, router, jsonRequest(string(body)))
+	if code != http.StatusOK || !second.Cached || second.Summary != first.Summary {
+		t.Errorf("repeated request: status %d, response %+v, want the cached summary", code, second)
+	}
+}
+
+func TestSummarizeHandlerUpload(t *testing.T) {
+	router := testRouter(t)
+
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		file        string
+	}{
+		{"plain text", "note.txt", "text/plain", testArticleContent},
+		{"html", "page.html", "text/html", "<html><body><nav>Markets | Stocks</nav><article><p>" +
+			strings.ReplaceAll(testArticleContent, ". ", ".</p><p>") + "</p></article></body></html>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := uploadRequest(t, map[string]string{"maxSentences": "2"}, tt.filename, tt.contentType, []byte(tt.file))
+			code, response := serve(t, router, req)
+			if code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", code)
+			}
+			if len(response.Sentences) != 2 {
+				t.Errorf("response = %+v, want 2 sentences", response)
+			}
+			for _, sentence := range response.Sentences {
+				if !strings.Contains(testArticleContent, sentence) {
+					t.Errorf("sentence %q is not from the document", sentence)
+				}
+			}
+		})
+	}
+}
+
+func TestSummarizeHandlerErrors(t *testing.T) {
+	router := testRouter(t)
+	pdf := []byte("%PDF-1.7\n1 0 obj << /Type /Catalog >> endobj")
+	oversized := strings.Repeat("Profit rose. ", (services.MaxDocumentBytes+uploadOverhead)/13+1)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"nothing to summarize", jsonRequest(`{"maxSentences": 2}`), http.StatusBadRequest},
+		{"url and text", jsonRequest(`{"url": "https://www.livemint.com/a", "text": "Profit rose."}`), http.StatusBadRequest},
+		{"text and file", uploadRequest(t, map[string]string{"text": "Profit rose."}, "note.txt", "text/plain", []byte("Sales fell.")), http.StatusBadRequest},
+		{"invalid json", jsonRequest(`{"text": `), http.StatusBadRequest},
+		{"invalid options", jsonRequest(`{"text": "Profit rose.", "ratio": 2}`), http.StatusBadRequest},
+		{"url not allowed", jsonRequest(`{"url": "https://example.com/article"}`), http.StatusBadRequest},
+		{"oversized body", jsonRequest(`{"text": "` + oversized + `"}`), http.StatusRequestEntityTooLarge},
+		{"oversized upload", uploadRequest(t, nil, "big.txt", "text/plain", []byte(oversized)), http.StatusRequestEntityTooLarge},
+		{"pdf upload", uploadRequest(t, nil, "filing.pdf", "application/pdf", pdf), http.StatusUnsupportedMediaType},
+		{"empty text", uploadRequest(t, nil, "blank.txt", "text/plain", []byte("  \n ")), http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _ := serve(t, router, tt.req); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
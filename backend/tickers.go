@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"stock-news-aggregator/internal/services"
+	"stock-news-aggregator/internal/tickers"
+)
+
+// bindCompany resolves the :symbol path parameter, which may also be a
+// company name or alias, writing a 404 response if it is unknown
+func bindCompany(c *gin.Context) (tickers.Company, bool) {
+	company, ok := services.LookupCompany(c.Param("symbol"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown ticker", "symbol": c.Param("symbol")})
+	}
+	return company, ok
+}
+
+// getTickerNews pages through the articles linked to a company. It accepts
+// the same filter and pagination parameters as /api/news/db.
+func getTickerNews(c *gin.Context) {
+	company, ok := bindCompany(c)
+	if !ok {
+		return
+	}
+	filter, ok := bindArticleFilter(c)
+	if !ok {
+		return
+	}
+	writeNewsPage(c, services.WithTicker(filter, company.Symbol))
+}
+
+func getTickerTimeline(c *gin.Context) {
+	company, ok := bindCompany(c)
+	if !ok {
+		return
+	}
+
+	rangeName := c.DefaultQuery("range", "3mo")
+	if err := services.ParseTimelineRange(rangeName); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timeline, err := services.GetTickerTimeline(company, rangeName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, timeline)
+}
@@ -1,4 +1,4 @@
-import React, { useState } from 'react';
+import React, { useState, useEffect } from 'react';
 import { useLocation, useNavigate } from 'react-router-dom';
 import { 
   Box, 
@@ -16,7 +16,15 @@ import {
   DialogContent,
   DialogActions,
   CircularProgress,
-  Stack
+  Stack,
+  List,
+  ListItemButton,
+  ListItemText,
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableRow
 } from '@mui/material';
 import ArrowBackIcon from '@mui/icons-material/ArrowBack';
 import AccessTimeIcon from '@mui/icons-material/AccessTime';
@@ -28,11 +36,24 @@ import axios from 'axios';
 const ArticleView = () => {
   const location = useLocation();
   const navigate = useNavigate();
-  const article = location.state?.article;
+  const [article, setArticle] = useState(location.state?.article);
   const [summary, setSummary] = useState('');
   const [summaryOpen, setSummaryOpen] = useState(false);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState(null);
+  const [related, setRelated] = useState([]);
+
+  // Load the full stored article, including content, by its ID
+  const articleId = location.pathname.split('/').filter(Boolean)[1];
+  useEffect(() => {
+    if (!articleId) return;
+    axios.get(`http://localhost:8080/api/news/${articleId}`)
+      .then((response) => setArticle(response.data))
+      .catch((err) => console.error('Error loading article:', err));
+    axios.get(`http://localhost:8080/api/news/${articleId}/related`, { params: { limit: 5 } })
+      .then((response) => setRelated(response.data.related || []))
+      .catch((err) => console.error('Error loading related articles:', err));
+  }, [articleId]);
 
   // Get the article URL, handling both cases
   const articleUrl = article?.URL || article?.url;
@@ -177,7 +198,7 @@ const ArticleView = () => {
             />
           </Box>
 
-          {article.imageURL && (
+          {article.urlToImage && (
             <Box 
               sx={{ 
                 width: '100%',
@@ -190,7 +211,7 @@ const ArticleView = () => {
             >
               <Box
                 component="img"
-                src={article.imageURL}
+                src={article.urlToImage}
                 alt={article.title}
                 sx={{
                   width: '100%',
@@ -262,6 +283,63 @@ const ArticleView = () => {
             </Button>
           </Box>
         </Paper>
+
+        {article.facts?.length > 0 && (
+          <Paper sx={{ p: 3, mt: 3 }}>
+            <Typography variant="h6" sx={{ mb: 1 }}>
+              Key Figures
+            </Typography>
+            <Table size="small">
+              <TableHead>
+                <TableRow>
+                  <TableCell>Figure</TableCell>
+                  <TableCell>What</TableCell>
+                  <TableCell>Period</TableCell>
+                  <TableCell>Company</TableCell>
+                  <TableCell align="right">Value</TableCell>
+                </TableRow>
+              </TableHead>
+              <TableBody>
+                {article.facts.map((fact, index) => (
+                  <TableRow key={index} title={fact.context}>
+                    <TableCell>{fact.text}</TableCell>
+                    <TableCell>{fact.kind === 'price_target' ? 'Target price' : fact.label || '-'}</TableCell>
+                    <TableCell>{fact.period || '-'}</TableCell>
+                    <TableCell>{fact.symbol || '-'}</TableCell>
+                    <TableCell align="right">
+                      {fact.unit === '%'
+                        ? `${fact.value > 0 ? '+' : ''}${fact.value}%`
+                        : `${fact.unit} ${fact.value.toLocaleString('en-IN')}`}
+                    </TableCell>
+                  </TableRow>
+                ))}
+              </TableBody>
+            </Table>
+          </Paper>
+        )}
+
+        {related.length > 0 && (
+          <Paper sx={{ p: 3, mt: 3 }}>
+            <Typography variant="h6" sx={{ mb: 1 }}>
+              Related Articles
+            </Typography>
+            <List disablePadding>
+              {related.map(({ article: relatedArticle }) => (
+                <ListItemButton
+                  key={relatedArticle.id}
+                  onClick={() => navigate(`/article/${relatedArticle.id}`, {
+                    state: { article: relatedArticle, from: location.state?.from }
+                  })}
+                >
+                  <ListItemText
+                    primary={relatedArticle.title}
+                    secondary={`${relatedArticle.source?.name || ''} · ${formatDate(relatedArticle.publishedAt)}`}
+                  />
+                </ListItemButton>
+              ))}
+            </List>
+          </Paper>
+        )}
       </Box>
 
       <Dialog
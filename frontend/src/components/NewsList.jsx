@@ -9,8 +9,6 @@ import {
   Skeleton,
   Alert,
   Paper,
-  TextField,
-  InputAdornment,
   Pagination,
   Stack,
   Select,
@@ -18,8 +16,8 @@ import {
   FormControl,
   InputLabel
 } from '@mui/material';
-import SearchIcon from '@mui/icons-material/Search';
 import axios from 'axios';
+import SearchBox from './SearchBox';
 
 const API_BASE_URL = 'http://localhost:8080';
 
@@ -125,8 +123,7 @@ const NewsList = () => {
     });
   };
 
-  const handleSearchChange = (event) => {
-    const newQuery = event.target.value;
+  const handleSearch = (newQuery) => {
     console.log(`Search query changed to: "${newQuery}"`);
     setSearchQuery(newQuery);
     setPage(1); // Reset to first page on search
@@ -219,6 +216,32 @@ const NewsList = () => {
             >
               {article.description || 'No description available'}
             </Typography>
+            {article.summary && (
+              <Box
+                sx={{
+                  mt: 1.5,
+                  pl: 1.5,
+                  borderLeft: 3,
+                  borderColor: 'primary.light'
+                }}
+              >
+                <Typography variant="caption" color="primary" sx={{ fontWeight: 600 }}>
+                  Summary
+                </Typography>
+                <Typography
+                  variant="body2"
+                  sx={{
+                    display: '-webkit-box',
+                    WebkitLineClamp: 4,
+                    WebkitBoxOrient: 'vertical',
+                    overflow: 'hidden',
+                    lineHeight: 1.5
+                  }}
+                >
+                  {article.summary}
+                </Typography>
+              </Box>
+            )}
           </CardContent>
         </CardActionArea>
       </Card>
@@ -254,19 +277,10 @@ const NewsList = () => {
           alignItems: 'center'
         }}
       >
-        <TextField
-          fullWidth
-          variant="outlined"
+        <SearchBox
+          initialValue={searchQuery}
           placeholder="Search articles..."
-          value={searchQuery}
-          onChange={handleSearchChange}
-          InputProps={{
-            startAdornment: (
-              <InputAdornment position="start">
-                <SearchIcon />
-              </InputAdornment>
-            ),
-          }}
+          onSearch={handleSearch}
           sx={{ flex: 1 }}
         />
         <FormControl sx={{ minWidth: 120 }}>
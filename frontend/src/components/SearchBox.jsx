@@ -1,38 +1,87 @@
-import { useState } from 'react';
-import { TextField, InputAdornment } from '@mui/material';
+import { useState, useEffect } from 'react';
+import { TextField, InputAdornment, Autocomplete } from '@mui/material';
 import SearchIcon from '@mui/icons-material/Search';
+import axios from 'axios';
 
-const SearchBox = ({ onSearch }) => {
-  const [searchText, setSearchText] = useState('');
+const API_BASE_URL = 'http://localhost:8080';
 
-  const handleSearchChange = (event) => {
-    const value = event.target.value;
-    setSearchText(value);
-    onSearch(value);
-  };
+// Suggestions are fetched as the user types; a search only runs when a
+// suggestion is picked or Enter is pressed.
+const SearchBox = ({ onSearch, initialValue = '', placeholder = 'Search news...', sx }) => {
+  const [searchText, setSearchText] = useState(initialValue);
+  const [suggestions, setSuggestions] = useState([]);
+
+  useEffect(() => {
+    const prefix = searchText.trim();
+    if (!prefix) {
+      setSuggestions([]);
+      return;
+    }
+
+    const controller = new AbortController();
+    const debounceTimer = setTimeout(async () => {
+      try {
+        const response = await axios.get(`${API_BASE_URL}/api/search/suggest`, {
+          params: { q: prefix, limit: 8 },
+          signal: controller.signal
+        });
+        setSuggestions(response.data.suggestions || []);
+      } catch (err) {
+        if (!axios.isCancel(err)) {
+          console.error('Error fetching suggestions:', err);
+        }
+      }
+    }, 150);
+
+    return () => {
+      clearTimeout(debounceTimer);
+      controller.abort();
+    };
+  }, [searchText]);
 
   return (
-    <TextField
-      placeholder="Search news..."
-      value={searchText}
-      onChange={handleSearchChange}
-      size="small"
+    <Autocomplete
+      freeSolo
       fullWidth
-      sx={{
-        maxWidth: '300px',
-        '& .MuiOutlinedInput-root': {
-          backgroundColor: 'background.paper',
+      options={suggestions}
+      filterOptions={(options) => options}
+      getOptionLabel={(option) => (typeof option === 'string' ? option : option.text)}
+      groupBy={(option) => option.kind}
+      inputValue={searchText}
+      onInputChange={(event, value, reason) => {
+        setSearchText(value);
+        if (reason === 'clear') {
+          onSearch('');
         }
       }}
-      InputProps={{
-        startAdornment: (
-          <InputAdornment position="start">
-            <SearchIcon />
-          </InputAdornment>
-        ),
+      onChange={(event, value) => {
+        const query = typeof value === 'string' ? value : value?.text || '';
+        setSearchText(query);
+        onSearch(query);
       }}
+      sx={sx}
+      renderInput={(params) => (
+        <TextField
+          {...params}
+          placeholder={placeholder}
+          size="small"
+          sx={{
+            '& .MuiOutlinedInput-root': {
+              backgroundColor: 'background.paper',
+            }
+          }}
+          InputProps={{
+            ...params.InputProps,
+            startAdornment: (
+              <InputAdornment position="start">
+                <SearchIcon />
+              </InputAdornment>
+            ),
+          }}
+        />
+      )}
     />
   );
 };
 
-export default SearchBox; 
\ No newline at end of file
+export default SearchBox;